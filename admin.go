@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// adminKeyHeader carries ADMIN_API_KEY. It's separate from Authorization so
+// the operator's key never mixes with user API keys.
+const adminKeyHeader = "X-Admin-Key"
+
+// ClearCacheResponse reports how many cached results were removed.
+type ClearCacheResponse struct {
+	Prefix  string `json:"prefix,omitempty"`
+	Removed int    `json:"removed"`
+}
+
+// AdminRoutes mounts the operator endpoints, all behind RequireAdmin.
+func AdminRoutes(r chi.Router) {
+	r.Use(RequireAdmin)
+	r.Get("/cache", GetCacheStats)
+	r.Delete("/cache", ClearCache)
+	r.Get("/usage", GetUsage)
+}
+
+// RequireAdmin rejects requests without ADMIN_API_KEY in the X-Admin-Key
+// header. The admin endpoints don't exist while ADMIN_API_KEY is unset.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adminKey := getEnv("ADMIN_API_KEY", "")
+		if adminKey == "" {
+			render.Render(w, r, ErrNotFound(errors.New("admin endpoints are disabled")))
+			return
+		}
+		key := r.Header.Get(adminKeyHeader)
+		if key == "" {
+			render.Render(w, r, ErrUnauthorized(errors.New("the "+adminKeyHeader+" header is required")))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			render.Render(w, r, ErrForbidden(errors.New("invalid admin key")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin reports whether r carries ADMIN_API_KEY in the X-Admin-Key header.
+func isAdmin(r *http.Request) bool {
+	adminKey, key := getEnv("ADMIN_API_KEY", ""), r.Header.Get(adminKeyHeader)
+	return adminKey != "" && key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1
+}
+
+// GetCacheStats godoc
+// @Summary      Show result cache statistics
+// @Description  Returns the cache's size, limits, hit and miss counts, evictions and the age of its oldest entry
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminKey
+// @Success      200  {object}  CacheStats
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Failure      404  {object}  ErrResponse
+// @Router       /admin/cache [get]
+func GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, weightCache.Stats())
+}
+
+// ClearCache godoc
+// @Summary      Clear cached results
+// @Description  Removes every cached result, or only those whose key starts with prefix: "get:" for GET /rack with the default plates, "post:" for custom plates and inventories
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminKey
+// @Param        prefix  query     string  false  "Only clear keys with this prefix, such as get: or post:"
+// @Success      200     {object}  ClearCacheResponse
+// @Failure      401     {object}  ErrResponse
+// @Failure      403     {object}  ErrResponse
+// @Failure      404     {object}  ErrResponse
+// @Router       /admin/cache [delete]
+func ClearCache(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	render.JSON(w, r, &ClearCacheResponse{Prefix: prefix, Removed: weightCache.ClearPrefix(prefix)})
+}
+
+// GetUsage godoc
+// @Summary      Show today's requests per API key
+// @Description  Lists each API key's rate-limited requests so far today (UTC), busiest first, with the daily quota when DAILY_QUOTA is set
+// @Tags         Admin
+// @Produce      json
+// @Security     AdminKey
+// @Success      200  {array}   KeyUsage
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Failure      404  {object}  ErrResponse
+// @Router       /admin/usage [get]
+func GetUsage(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, rateLimiter.Usage())
+}
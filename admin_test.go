@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// adminRouter mounts the admin routes and the calculations that fill the
+// cache behind Authenticate, the way serve does.
+func adminRouter() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/v1/api", func(r chi.Router) {
+		r.Use(Authenticate)
+		r.Post("/rack", RackEmPost)
+		r.Get("/rack", RackEmGet)
+		r.Route("/admin", AdminRoutes)
+	})
+	return router
+}
+
+func TestRequireAdmin(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	router := adminRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	if rec := serveRequest(router, "GET", "/v1/api/admin/cache", "", "", adminKeyHeader, "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("without ADMIN_API_KEY: %d, want 404", rec.Code)
+	}
+
+	t.Setenv("ADMIN_API_KEY", "secret")
+	tests := []struct {
+		name     string
+		token    string
+		adminKey string
+		want     int
+	}{
+		{"admin key", "", "secret", http.StatusOK},
+		{"no key", "", "", http.StatusUnauthorized},
+		{"user key", token, "", http.StatusUnauthorized},
+		{"user key as admin key", "", token, http.StatusForbidden},
+		{"wrong admin key", token, "not-the-secret", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{"GET", "DELETE"} {
+				if rec := serveRequest(router, method, "/v1/api/admin/cache", "", tt.token, adminKeyHeader, tt.adminKey); rec.Code != tt.want {
+					t.Errorf("%s /admin/cache: %d, want %d", method, rec.Code, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminCache(t *testing.T) {
+	useTestStore(t)
+	cache := useTestCache(t, CacheConfig{TTL: time.Minute})
+	t.Setenv("ADMIN_API_KEY", "secret")
+	router := adminRouter()
+
+	post := func() string {
+		t.Helper()
+		rec := serveRequest(router, "POST", "/v1/api/rack", `{"desiredWeight": 135, "fortyFives": 2}`, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /rack: %d %s", rec.Code, rec.Body)
+		}
+		return rec.Header().Get("X-Cache")
+	}
+	if first, second := post(), post(); first != "MISS" || second != "HIT" {
+		t.Errorf("X-Cache = %q, then %q, want MISS, then HIT", first, second)
+	}
+	for _, target := range []string{"/v1/api/rack?weight=225", "/v1/api/rack?weight=315"} {
+		if rec := serveRequest(router, "GET", target, "", ""); rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "MISS" {
+			t.Fatalf("GET %s: %d, X-Cache %q", target, rec.Code, rec.Header().Get("X-Cache"))
+		}
+	}
+
+	rec := serveRequest(router, "GET", "/v1/api/admin/cache", "", "", adminKeyHeader, "secret")
+	var stats CacheStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 3 || stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("stats = %+v, want 3 entries, 1 hit and 3 misses", stats)
+	}
+
+	clearPrefix := func(query string) ClearCacheResponse {
+		t.Helper()
+		rec := serveRequest(router, "DELETE", "/v1/api/admin/cache"+query, "", "", adminKeyHeader, "secret")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("DELETE /admin/cache%s: %d %s", query, rec.Code, rec.Body)
+		}
+		var response ClearCacheResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	if got := clearPrefix("?prefix=get:"); got.Prefix != "get:" || got.Removed != 2 {
+		t.Errorf("clearing get: = %+v, want 2 removed", got)
+	}
+	if cache.Len() != 1 || post() != "HIT" {
+		t.Errorf("clearing get: left %d entries, want the POST one", cache.Len())
+	}
+	if got := clearPrefix("?prefix=post:"); got.Removed != 1 || cache.Len() != 0 {
+		t.Errorf("clearing post: = %+v with %d entries left, want 1 removed", got, cache.Len())
+	}
+	if post() != "MISS" {
+		t.Error("POST /rack still cached after clearing post:")
+	}
+	if got := clearPrefix(""); got.Prefix != "" || got.Removed != 1 || cache.Len() != 0 {
+		t.Errorf("clearing everything = %+v with %d entries left", got, cache.Len())
+	}
+}
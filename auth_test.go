@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// accountRouter mounts the account and inventory routes the way serve does.
+func accountRouter() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/v1/api", func(r chi.Router) {
+		r.Use(Authenticate)
+		r.Route("/users", UserRoutes)
+		r.Route("/keys", APIKeyRoutes)
+		r.Route("/inventories", InventoryRoutes)
+	})
+	return router
+}
+
+// putSharedInventory saves a profile with no owner, as stores from before
+// accounts have.
+func putSharedInventory(t *testing.T) *Inventory {
+	t.Helper()
+	now := time.Now().UTC()
+	inv := &Inventory{ID: "shared", Name: "gym", BarWeight: 45, FortyFives: 4, CreatedAt: now, UpdatedAt: now}
+	if err := dataStore.PutInventory(inv); err != nil {
+		t.Fatalf("PutInventory: %v", err)
+	}
+	return inv
+}
+
+func TestAuthenticate(t *testing.T) {
+	useTestStore(t)
+	router := accountRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	revokedUser, revoked := newTestUser(t, "old", AllScopes...)
+	for _, key := range dataStore.ListAPIKeys(revokedUser.ID) {
+		if err := revokeAPIKey(key); err != nil {
+			t.Fatalf("revokeAPIKey: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		status  int
+		error   string
+	}{
+		{"no key", nil, http.StatusUnauthorized, "an API key is required"},
+		{"wrong scheme", []string{"Authorization", "Basic " + token}, http.StatusUnauthorized, "authorization header must use the Bearer scheme"},
+		{"malformed key", []string{"Authorization", "Bearer nope"}, http.StatusUnauthorized, "invalid API key"},
+		{"wrong secret", []string{"Authorization", "Bearer " + token + "0"}, http.StatusUnauthorized, "invalid API key"},
+		{"revoked key", []string{"Authorization", "Bearer " + revoked}, http.StatusUnauthorized, "API key has been revoked"},
+		{"valid key", []string{"Authorization", "Bearer " + token}, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(router, "GET", "/v1/api/users/me", "", "", tt.headers...)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.error == "" {
+				return
+			}
+			if got := decodeError(t, rec); got.ErrorText != tt.error || got.StatusText != "Unauthorized." {
+				t.Errorf("error = %q (%q), want %q", got.ErrorText, got.StatusText, tt.error)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	useTestStore(t)
+	router := accountRouter()
+	_, readOnly := newTestUser(t, "reader", ScopeRead)
+
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{"GET", "/v1/api/inventories", "", http.StatusOK},
+		{"POST", "/v1/api/inventories", `{"name":"garage"}`, http.StatusForbidden},
+		{"GET", "/v1/api/keys", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := serveRequest(router, tt.method, tt.target, tt.body, readOnly)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusForbidden && decodeError(t, rec).StatusText != "Forbidden." {
+				t.Errorf("body = %s, want a Forbidden error", rec.Body)
+			}
+		})
+	}
+}
+
+func TestInventoryOwnership(t *testing.T) {
+	useTestStore(t)
+	t.Setenv("ADMIN_API_KEY", "operator")
+	router := accountRouter()
+	_, owner := newTestUser(t, "owner", AllScopes...)
+	_, other := newTestUser(t, "other", AllScopes...)
+
+	rec := serveRequest(router, "POST", "/v1/api/inventories", `{"name":"garage","fortyFives":2}`, owner)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body)
+	}
+	var inv Inventory
+	if err := json.Unmarshal(rec.Body.Bytes(), &inv); err != nil {
+		t.Fatal(err)
+	}
+	path := "/v1/api/inventories/" + inv.ID
+
+	// Someone else's profile doesn't exist as far as other callers can tell
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		if rec := serveRequest(router, method, path, `{"name":"mine now"}`, other); rec.Code != http.StatusNotFound {
+			t.Errorf("%s by another user: status = %d, want 404", method, rec.Code)
+		}
+	}
+	if rec := serveRequest(router, "GET", "/v1/api/inventories", "", other); rec.Body.String() != "[]\n" {
+		t.Errorf("list by another user = %s, want []", rec.Body)
+	}
+	if rec := serveRequest(router, "PUT", path, `{"name":"home"}`, owner); rec.Code != http.StatusOK {
+		t.Errorf("PUT by owner: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serveRequest(router, "DELETE", path, "", owner); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE by owner: status = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSharedInventoryIsReadOnly(t *testing.T) {
+	useTestStore(t)
+	t.Setenv("ADMIN_API_KEY", "operator")
+	router := accountRouter()
+	shared := putSharedInventory(t)
+	_, token := newTestUser(t, "sam", AllScopes...)
+	path := "/v1/api/inventories/" + shared.ID
+
+	if rec := serveRequest(router, "GET", path, "", token); rec.Code != http.StatusOK {
+		t.Errorf("GET: status = %d, want 200", rec.Code)
+	}
+	for _, method := range []string{"PUT", "DELETE"} {
+		rec := serveRequest(router, method, path, `{"name":"taken"}`, token)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s without admin key: status = %d, want 403", method, rec.Code)
+		}
+		if got := decodeError(t, rec).ErrorText; got != errSharedInventory.Error() {
+			t.Errorf("%s without admin key: error = %q", method, got)
+		}
+		if rec := serveRequest(router, method, path, `{"name":"taken"}`, token, adminKeyHeader, "wrong"); rec.Code != http.StatusForbidden {
+			t.Errorf("%s with a wrong admin key: status = %d, want 403", method, rec.Code)
+		}
+	}
+	if got, err := dataStore.GetInventory(shared.ID); err != nil || got.Name != "gym" {
+		t.Fatalf("shared profile changed: %+v, %v", got, err)
+	}
+
+	if rec := serveRequest(router, "PUT", path, `{"name":"main floor"}`, token, adminKeyHeader, "operator"); rec.Code != http.StatusOK {
+		t.Errorf("PUT with admin key: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serveRequest(router, "DELETE", path, "", token, adminKeyHeader, "operator"); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE with admin key: status = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSignup(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowSignup string
+		headers     []string
+		status      int
+	}{
+		{"disabled by default", "", nil, http.StatusForbidden},
+		{"disabled with a wrong admin key", "", []string{adminKeyHeader, "wrong"}, http.StatusForbidden},
+		{"admin key", "", []string{adminKeyHeader, "operator"}, http.StatusCreated},
+		{"enabled", "true", nil, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestStore(t)
+			t.Setenv("ADMIN_API_KEY", "operator")
+			t.Setenv("ALLOW_SIGNUP", tt.allowSignup)
+			rec := serveRequest(accountRouter(), "POST", "/v1/api/users", `{"name":"sam"}`, "", tt.headers...)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var signup struct {
+				APIKey APIKeyResponse `json:"apiKey"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &signup); err != nil {
+				t.Fatal(err)
+			}
+			if rec := serveRequest(accountRouter(), "GET", "/v1/api/users/me", "", signup.APIKey.Key); rec.Code != http.StatusOK {
+				t.Errorf("new key: status = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/pachev/gorack/rack"
+)
+
+// BatchRackRequest is a list of rack requests calculated in one call. Items
+// are kept raw so a malformed item only fails itself, not the whole batch.
+type BatchRackRequest []json.RawMessage
+
+// Bind validates the size of a batch.
+func (br BatchRackRequest) Bind(r *http.Request) error {
+	return validateBatchSize(len(br))
+}
+
+// validateBatchSize checks a batch isn't empty or over BATCH_MAX_SIZE.
+func validateBatchSize(size int) error {
+	if size == 0 {
+		return errors.New("batch must contain at least one request")
+	}
+	if maxSize := getEnvInt("BATCH_MAX_SIZE", 100); size > maxSize {
+		return fmt.Errorf("batch cannot contain more than %d requests", maxSize)
+	}
+	return nil
+}
+
+// BatchRackResult is the outcome of one item in a batch. Exactly one of
+// Result and Error is set.
+type BatchRackResult struct {
+	Index  int                         `json:"index"`
+	Result *rack.ReturnedValueStandard `json:"result,omitempty"`
+	Error  *ErrResponse                `json:"error,omitempty"`
+}
+
+// RackEmBatch godoc
+// @Summary      Calculate plates for many requests at once
+// @Description  Takes an array of rack requests, each with its own bar, plates or inventory, and returns results in the same order. Invalid items get a per-item error instead of failing the batch.
+// @Tags         Rack
+// @Accept       json
+// @Produce      json
+// @Param        request   body      []RackRequest  true   "Rack requests"
+// @Param        strategy  query     string         false  "Solver strategy for items that don't name one"
+// @Param        explain   query     bool           false  "Include every item's decision trace"
+// @Success      200      {array}   BatchRackResult
+// @Failure      400      {object}  ErrResponse
+// @Failure      429      {object}  ErrResponse
+// @Router       /rack/batch [post]
+func RackEmBatch(w http.ResponseWriter, r *http.Request) {
+	var items BatchRackRequest
+	if err := render.Bind(r, &items); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	// Limit took one token for the request; each item after the first costs another
+	if len(items) > 1 && !rateLimiter.Charge(w, r, len(items)-1) {
+		return
+	}
+
+	results := make([]BatchRackResult, len(items))
+	for i, raw := range items {
+		results[i] = BatchRackResult{Index: i}
+
+		request := &RackRequest{}
+		if err := json.Unmarshal(raw, request); err != nil {
+			results[i].Error = ErrInvalidRequest(err).(*ErrResponse)
+			continue
+		}
+		if err := request.Bind(r); err != nil {
+			results[i].Error = ErrInvalidRequest(err).(*ErrResponse)
+			continue
+		}
+		input := &request.RackInputStandard
+
+		result, _, err := calculate(r.Context(), generateCacheKey(input), input, request.Explain)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Calculating weight failed", "item", i, "err", err, "input", input)
+			results[i].Error = ErrCalculation(err).(*ErrResponse)
+			continue
+		}
+		results[i].Result = result
+	}
+
+	render.JSON(w, r, results)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postBatch posts a batch to RackEmBatch and decodes the results.
+func postBatch(t *testing.T, target, body string) []BatchRackResult {
+	t.Helper()
+	rec := serveRequest(http.HandlerFunc(RackEmBatch), "POST", target, body, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST %s: %d %s", target, rec.Code, rec.Body)
+	}
+	var results []BatchRackResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestRackEmBatch(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+	putSharedInventory(t)
+
+	results := postBatch(t, "/v1/api/rack/batch", `[
+		{"desiredWeight": 135, "fortyFives": 2},
+		"not a request",
+		{"desiredWeight": 45, "barWeight": 45},
+		{"desiredWeight": 225, "inventory": "missing"},
+		{"desiredWeight": 225, "inventory": "shared"},
+		{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2, "strategy": "exact"}
+	]`)
+
+	// Results come back in order, each with a result or its own error
+	want := []struct {
+		achieved int
+		error    string
+	}{
+		{achieved: 135},
+		{error: "json: cannot unmarshal string into Go value of type main.RackRequest"},
+		{error: "desired weight must be greater than bar weight"},
+		{error: "inventory not found"},
+		{achieved: 225},
+		{achieved: 185},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Index != i {
+			t.Errorf("result %d: index %d", i, got.Index)
+		}
+		if w.error != "" {
+			if got.Result != nil || got.Error == nil || got.Error.ErrorText != w.error || got.Error.StatusText != "Invalid request." {
+				t.Errorf("result %d = %+v, want error %q", i, got, w.error)
+			}
+			continue
+		}
+		if got.Error != nil || got.Result == nil || got.Result.AchievedWeight != w.achieved {
+			t.Errorf("result %d = %+v, want %d", i, got, w.achieved)
+		}
+	}
+	if results[5].Result.Strategy != "exact" {
+		t.Errorf("item strategy = %q, want exact", results[5].Result.Strategy)
+	}
+}
+
+func TestRackEmBatchQuery(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+
+	results := postBatch(t, "/v1/api/rack/batch?strategy=exact&explain=true", `[
+		{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2},
+		{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2, "strategy": "greedy"}
+	]`)
+	if results[0].Result.Strategy != "exact" || results[1].Result.Strategy != "greedy" {
+		t.Errorf("strategies = %q, %q, want exact from the query and greedy from the item", results[0].Result.Strategy, results[1].Result.Strategy)
+	}
+	if results[0].Result.Explanation == nil || results[1].Result.Explanation == nil {
+		t.Error("explain=true left an item without its explanation")
+	}
+}
+
+func TestRackEmBatchSize(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+	t.Setenv("BATCH_MAX_SIZE", "2")
+
+	tests := []struct {
+		name, body, error string
+	}{
+		{"empty", `[]`, "batch must contain at least one request"},
+		{"too big", `[` + strings.Repeat(`{"desiredWeight": 135},`, 2) + `{"desiredWeight": 135}]`, "batch cannot contain more than 2 requests"},
+		{"not a list", `{"desiredWeight": 135}`, "json: cannot unmarshal object into Go value of type main.BatchRackRequest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(http.HandlerFunc(RackEmBatch), "POST", "/v1/api/rack/batch", tt.body, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if got := decodeError(t, rec).ErrorText; got != tt.error {
+				t.Errorf("error = %q, want %q", got, tt.error)
+			}
+		})
+	}
+}
+
+func TestRackEmBatchSharesCache(t *testing.T) {
+	useTestStore(t)
+	cache := useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+
+	// POST /rack and batches share cache entries, including within one batch
+	rec := serveRequest(http.HandlerFunc(RackEmPost), "POST", "/v1/api/rack", `{"desiredWeight": 135, "fortyFives": 2}`, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("POST /rack: %d, X-Cache %q", rec.Code, rec.Header().Get("X-Cache"))
+	}
+	postBatch(t, "/v1/api/rack/batch", `[
+		{"desiredWeight": 135, "fortyFives": 2},
+		{"desiredWeight": 225, "fortyFives": 2},
+		{"desiredWeight": 225, "fortyFives": 2}
+	]`)
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 2 || stats.Calculations != 2 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want 2 hits, 2 misses and 2 entries", stats)
+	}
+}
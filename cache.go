@@ -0,0 +1,314 @@
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pachev/gorack/rack"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheConfig bounds a WeightCache. Zero values mean no limit.
+type CacheConfig struct {
+	TTL           time.Duration // How long an entry stays fresh
+	MaxEntries    int           // Most entries kept before the least recently used is evicted
+	MaxBytes      int           // Approximate memory budget, see entrySize
+	SweepInterval time.Duration // How often expired entries are swept, 0 for lazy expiry only
+}
+
+// cacheEntryOverhead approximates the memory used by an entry besides its
+// key and result: the entry struct, the list elements and the map slot.
+const cacheEntryOverhead = 256
+
+// WeightCache provides in-memory caching for weight calculations. It is a
+// least recently used cache: entries expire lazily when read after their
+// TTL, a background sweeper removes the ones nobody reads again, and the
+// oldest are evicted once the cache is over its entry or memory limit.
+type WeightCache struct {
+	mu      sync.Mutex
+	config  CacheConfig
+	entries map[string]*list.Element
+	lru     *list.List // Most recently used at the front
+	byAge   *list.List // Oldest created at the front
+	bytes   int
+	stop    chan struct{}
+	flights singleflight.Group // Calculations in progress, by key
+	now     func() time.Time   // time.Now, replaced in tests
+
+	hits, misses, evictions, expirations uint64
+	calculations, coalesced, tableHits   uint64
+}
+
+// CacheStats is a snapshot of a WeightCache's size and counters.
+type CacheStats struct {
+	Entries            int     `json:"entries"`
+	Bytes              int     `json:"bytes"` // Approximate, see entrySize
+	MaxEntries         int     `json:"maxEntries,omitempty"`
+	MaxBytes           int     `json:"maxBytes,omitempty"`
+	TTLSeconds         float64 `json:"ttlSeconds,omitempty"`
+	Hits               uint64  `json:"hits"`
+	Misses             uint64  `json:"misses"`
+	HitRate            float64 `json:"hitRate"`     // Hits over lookups, 0 before the first lookup
+	Evictions          uint64  `json:"evictions"`   // Removed to stay within the limits
+	Expirations        uint64  `json:"expirations"` // Removed after their TTL
+	OldestEntrySeconds float64 `json:"oldestEntrySeconds"`
+	Calculations       uint64  `json:"calculations"` // Solver runs after a miss
+	Coalesced          uint64  `json:"coalesced"`    // Misses that waited for an identical calculation instead
+	TableHits          uint64  `json:"tableHits"`    // Hits answered from the loading table, included in Hits
+}
+
+// cacheEntry is a cached result and its bookkeeping.
+type cacheEntry struct {
+	key     string
+	value   *rack.ReturnedValueStandard
+	size    int
+	created time.Time
+	expires time.Time     // Zero when the cache has no TTL
+	age     *list.Element // The entry's place in byAge
+}
+
+// NewWeightCache creates a cache with the given limits, starting the sweeper
+// when entries can expire.
+func NewWeightCache(config CacheConfig) *WeightCache {
+	wc := &WeightCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		byAge:   list.New(),
+		stop:    make(chan struct{}),
+		now:     time.Now,
+	}
+	if config.TTL > 0 && config.SweepInterval > 0 {
+		go wc.sweep(config.SweepInterval)
+	}
+	return wc
+}
+
+// Get retrieves a cached result if it exists and hasn't expired
+func (wc *WeightCache) Get(key string) (*rack.ReturnedValueStandard, bool) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	element, found := wc.entries[key]
+	if !found {
+		wc.misses++
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if wc.expired(entry, wc.now()) {
+		wc.remove(element)
+		wc.expirations++
+		wc.misses++
+		return nil, false
+	}
+	wc.hits++
+	wc.lru.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores a calculation result in the cache, replacing any result
+// already stored under key and evicting old entries to stay within limits.
+func (wc *WeightCache) Set(key string, value *rack.ReturnedValueStandard) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	now := wc.now()
+	entry := &cacheEntry{key: key, value: value, size: entrySize(key, value), created: now}
+	if wc.config.TTL > 0 {
+		entry.expires = now.Add(wc.config.TTL)
+	}
+	if element, found := wc.entries[key]; found {
+		wc.remove(element)
+	}
+	wc.entries[key] = wc.lru.PushFront(entry)
+	entry.age = wc.byAge.PushBack(entry)
+	wc.bytes += entry.size
+
+	for wc.overLimit() {
+		wc.remove(wc.lru.Back())
+		wc.evictions++
+	}
+}
+
+// GetOrCalculate returns the cached result for key, or calculates and stores
+// it on a miss. Concurrent misses for the same key share one calculation
+// and all get its result. hit reports whether the result came from the cache.
+func (wc *WeightCache) GetOrCalculate(key string, calculate func() (*rack.ReturnedValueStandard, error)) (result *rack.ReturnedValueStandard, hit bool, err error) {
+	if cached, found := wc.Get(key); found {
+		return cached, true, nil
+	}
+
+	ran := false
+	value, err, _ := wc.flights.Do(key, func() (any, error) {
+		ran = true
+		result, err := calculate()
+		if err != nil {
+			return nil, err
+		}
+		wc.Set(key, result)
+		return result, nil
+	})
+
+	wc.mu.Lock()
+	if ran {
+		wc.calculations++
+	} else {
+		wc.coalesced++
+	}
+	wc.mu.Unlock()
+
+	if err != nil {
+		return nil, false, err
+	}
+	return value.(*rack.ReturnedValueStandard), false, nil
+}
+
+// CountTableHit records a lookup answered by the loading table in front of
+// the cache, so the hit counts and hit rate cover every GET /rack.
+func (wc *WeightCache) CountTableHit() {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.hits++
+	wc.tableHits++
+}
+
+// Clear empties the cache, returning how many entries were removed
+func (wc *WeightCache) Clear() int {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	removed := wc.lru.Len()
+	wc.entries = make(map[string]*list.Element)
+	wc.lru.Init()
+	wc.byAge.Init()
+	wc.bytes = 0
+	return removed
+}
+
+// ClearPrefix removes the entries whose keys start with prefix, such as
+// "get:" or "post:", returning how many were removed.
+func (wc *WeightCache) ClearPrefix(prefix string) int {
+	if prefix == "" {
+		return wc.Clear()
+	}
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	removed := 0
+	for key, element := range wc.entries {
+		if strings.HasPrefix(key, prefix) {
+			wc.remove(element)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Stats returns the cache's current size and counters.
+func (wc *WeightCache) Stats() CacheStats {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	stats := CacheStats{
+		Entries:      wc.lru.Len(),
+		Bytes:        wc.bytes,
+		MaxEntries:   wc.config.MaxEntries,
+		MaxBytes:     wc.config.MaxBytes,
+		TTLSeconds:   wc.config.TTL.Seconds(),
+		Hits:         wc.hits,
+		Misses:       wc.misses,
+		Evictions:    wc.evictions,
+		Expirations:  wc.expirations,
+		Calculations: wc.calculations,
+		Coalesced:    wc.coalesced,
+		TableHits:    wc.tableHits,
+	}
+	if lookups := wc.hits + wc.misses; lookups > 0 {
+		stats.HitRate = float64(wc.hits) / float64(lookups)
+	}
+	if oldest := wc.byAge.Front(); oldest != nil {
+		stats.OldestEntrySeconds = wc.now().Sub(oldest.Value.(*cacheEntry).created).Seconds()
+	}
+	return stats
+}
+
+// TTL returns how long entries stay fresh, 0 when they don't expire.
+func (wc *WeightCache) TTL() time.Duration {
+	return wc.config.TTL
+}
+
+// Len returns the number of entries, including expired ones not yet swept.
+func (wc *WeightCache) Len() int {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return wc.lru.Len()
+}
+
+// Close stops the sweeper. The cache can still be used, with lazy expiry only.
+func (wc *WeightCache) Close() {
+	select {
+	case <-wc.stop:
+	default:
+		close(wc.stop)
+	}
+}
+
+// sweep removes expired entries every interval until Close is called.
+func (wc *WeightCache) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wc.stop:
+			return
+		case <-ticker.C:
+			wc.removeExpired(wc.now())
+		}
+	}
+}
+
+// removeExpired drops every entry that has expired by now. Every entry
+// gets the same TTL, so they expire in the order they were created and the
+// sweep can stop at the first one that's still fresh.
+func (wc *WeightCache) removeExpired(now time.Time) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	for element := wc.byAge.Front(); element != nil; element = wc.byAge.Front() {
+		entry := element.Value.(*cacheEntry)
+		if !wc.expired(entry, now) {
+			return
+		}
+		wc.remove(wc.entries[entry.key])
+		wc.expirations++
+	}
+}
+
+func (wc *WeightCache) expired(entry *cacheEntry, now time.Time) bool {
+	return !entry.expires.IsZero() && now.After(entry.expires)
+}
+
+func (wc *WeightCache) overLimit() bool {
+	if wc.lru.Len() == 0 {
+		return false
+	}
+	return (wc.config.MaxEntries > 0 && wc.lru.Len() > wc.config.MaxEntries) ||
+		(wc.config.MaxBytes > 0 && wc.bytes > wc.config.MaxBytes)
+}
+
+// remove deletes an entry. The caller must hold wc.mu.
+func (wc *WeightCache) remove(element *list.Element) {
+	entry := wc.lru.Remove(element).(*cacheEntry)
+	wc.byAge.Remove(entry.age)
+	delete(wc.entries, entry.key)
+	wc.bytes -= entry.size
+}
+
+// entrySize approximates the memory an entry holds on to by the size of the
+// result's JSON, which grows with an explanation's steps, plus its key and
+// the fixed overhead. It's computed once, when the entry is stored.
+func entrySize(key string, value *rack.ReturnedValueStandard) int {
+	body, _ := json.Marshal(value)
+	return cacheEntryOverhead + len(key) + len(body)
+}
+
+// Global cache instance
+var weightCache *WeightCache
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// newTestCache returns a cache on a fake clock.
+func newTestCache(t *testing.T, config CacheConfig) (*WeightCache, *fakeClock) {
+	t.Helper()
+	clock := newFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	cache := NewWeightCache(CacheConfig{})
+	cache.config = config
+	cache.now = clock.Now
+	if config.TTL > 0 && config.SweepInterval > 0 {
+		go cache.sweep(config.SweepInterval)
+	}
+	t.Cleanup(cache.Close)
+	return cache, clock
+}
+
+// result calculates weight with the default plates, with the decision trace
+// when explain is set.
+func result(t *testing.T, weight int, explain bool) *rack.ReturnedValueStandard {
+	t.Helper()
+	input := rack.AssumeDefaults()
+	input.DesiredWeight = weight
+	calculate := rack.CalculateWeight
+	if explain {
+		calculate = rack.Explain
+	}
+	result, err := calculate(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestWeightCacheLRUEviction(t *testing.T) {
+	cache, _ := newTestCache(t, CacheConfig{MaxEntries: 2})
+	cache.Set("a", result(t, 135, false))
+	cache.Set("b", result(t, 225, false))
+	if _, found := cache.Get("a"); !found { // a is now the most recently used
+		t.Fatal("a missing")
+	}
+	cache.Set("c", result(t, 315, false))
+
+	if _, found := cache.Get("b"); found {
+		t.Error("b, the least recently used, wasn't evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.HitRate != 0.75 {
+		t.Errorf("hit rate = %v, want 0.75", stats.HitRate)
+	}
+}
+
+func TestWeightCacheMaxBytes(t *testing.T) {
+	plain, explained := result(t, 225, false), result(t, 225, true)
+	plainSize, explainedSize := entrySize("p1", plain), entrySize("e1", explained)
+	if explainedSize <= plainSize+len(explained.Explanation.Steps)*20 {
+		t.Fatalf("entrySize doesn't count explanation steps: %d plain, %d with %d steps", plainSize, explainedSize, len(explained.Explanation.Steps))
+	}
+
+	cache, _ := newTestCache(t, CacheConfig{MaxBytes: explainedSize + plainSize})
+	cache.Set("p1", plain)
+	cache.Set("p2", plain)
+	if got := cache.Stats().Bytes; got != 2*plainSize {
+		t.Fatalf("bytes = %d, want %d", got, 2*plainSize)
+	}
+
+	// An explanation is big enough to push out the least recently used plain result
+	cache.Set("e1", explained)
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Bytes != plainSize+explainedSize || stats.Evictions != 1 {
+		t.Errorf("after an explained result: %+v", stats)
+	}
+	if _, found := cache.Get("p1"); found {
+		t.Error("p1 should have been evicted")
+	}
+
+	// Replacing an entry swaps its size rather than adding to it
+	cache.Set("e1", plain)
+	if got := cache.Stats().Bytes; got != 2*entrySize("p2", plain) {
+		t.Errorf("bytes after replacing = %d, want %d", got, 2*plainSize)
+	}
+
+	// An entry too big on its own doesn't stay
+	small, _ := newTestCache(t, CacheConfig{MaxBytes: plainSize})
+	small.Set("e1", explained)
+	if stats := small.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("oversized entry kept: %+v", stats)
+	}
+
+	if cache.Clear() != 2 || cache.Stats().Bytes != 0 {
+		t.Errorf("Clear left %+v", cache.Stats())
+	}
+}
+
+func TestWeightCacheTTL(t *testing.T) {
+	cache, clock := newTestCache(t, CacheConfig{TTL: time.Minute})
+	cache.Set("a", result(t, 135, false))
+
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("a"); !found {
+		t.Fatal("expired at exactly the TTL")
+	}
+	clock.Advance(time.Second)
+	if _, found := cache.Get("a"); found {
+		t.Fatal("served after the TTL")
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Expirations != 1 || stats.Misses != 1 || stats.Bytes != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWeightCacheSweep(t *testing.T) {
+	cache, clock := newTestCache(t, CacheConfig{TTL: time.Minute, SweepInterval: time.Millisecond})
+	cache.Set("old", result(t, 135, false))
+	clock.Advance(30 * time.Second)
+	cache.Set("newer", result(t, 225, false))
+	clock.Advance(31 * time.Second) // old has expired, newer hasn't
+
+	eventually(t, func() bool { return cache.Len() == 1 }, "sweeper didn't remove the expired entry")
+	if _, found := cache.Get("newer"); !found {
+		t.Error("sweeper removed a fresh entry")
+	}
+	if stats := cache.Stats(); stats.Expirations != 1 || stats.Misses != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	clock.Advance(time.Minute)
+	eventually(t, func() bool { return cache.Len() == 0 }, "sweeper didn't remove the last entry")
+
+	cache.Close()
+	cache.Close() // Closing twice is fine
+}
+
+func TestWeightCacheOldestEntry(t *testing.T) {
+	cache, clock := newTestCache(t, CacheConfig{})
+	if got := cache.Stats().OldestEntrySeconds; got != 0 {
+		t.Errorf("empty cache: oldest = %v", got)
+	}
+	cache.Set("first", result(t, 135, false))
+	clock.Advance(10 * time.Second)
+	cache.Set("second", result(t, 225, false))
+	clock.Advance(10 * time.Second)
+
+	// Reading first makes it the most recently used, but not the newest
+	cache.Get("first")
+	if got := cache.Stats().OldestEntrySeconds; got != 20 {
+		t.Errorf("oldest = %v, want 20", got)
+	}
+
+	// Storing it again restarts its age
+	cache.Set("first", result(t, 135, false))
+	if got := cache.Stats().OldestEntrySeconds; got != 10 {
+		t.Errorf("oldest after replacing = %v, want 10", got)
+	}
+}
+
+func TestWeightCacheClearPrefix(t *testing.T) {
+	cache, _ := newTestCache(t, CacheConfig{})
+	for _, key := range []string{"get:greedy:135", "get:exact:135", "post:abc"} {
+		cache.Set(key, result(t, 135, false))
+	}
+	if removed := cache.ClearPrefix("get:"); removed != 2 {
+		t.Errorf("removed %d, want 2", removed)
+	}
+	if _, found := cache.Get("post:abc"); !found || cache.Len() != 1 {
+		t.Errorf("post:abc missing or extra entries left: %d", cache.Len())
+	}
+	if got := cache.Stats().Bytes; got != entrySize("post:abc", result(t, 135, false)) {
+		t.Errorf("bytes = %d after ClearPrefix", got)
+	}
+}
+
+func TestGetOrCalculateCoalescesConcurrentMisses(t *testing.T) {
+	const callers = 8
+	cache, _ := newTestCache(t, CacheConfig{})
+	want := result(t, 225, false)
+
+	// The solver blocks until every caller is waiting on it
+	var runs atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	calculate := func() (*rack.ReturnedValueStandard, error) {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return want, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*rack.ReturnedValueStandard, callers)
+	hits := make([]bool, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], hits[i], errs[i] = cache.GetOrCalculate("get:greedy:225", calculate)
+		}()
+	}
+
+	<-started
+	// Every caller has missed once it's been counted. The step from the miss
+	// into the flight isn't observable, so give the last ones a moment to
+	// join before letting the solver finish
+	eventually(t, func() bool { return cache.Stats().Misses == callers }, "callers didn't all miss")
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("solver ran %d times, want 1", got)
+	}
+	for i := range callers {
+		if errs[i] != nil || hits[i] || results[i] != want {
+			t.Errorf("caller %d got %p, hit %v, err %v", i, results[i], hits[i], errs[i])
+		}
+	}
+	stats := cache.Stats()
+	if stats.Calculations != 1 || stats.Coalesced != callers-1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 calculation and %d coalesced", stats, callers-1)
+	}
+
+	// Later callers are plain hits
+	if _, hit, _ := cache.GetOrCalculate("get:greedy:225", calculate); !hit || runs.Load() != 1 {
+		t.Error("result wasn't cached")
+	}
+}
+
+func TestGetOrCalculateSharesErrors(t *testing.T) {
+	cache, _ := newTestCache(t, CacheConfig{})
+	failure := errors.New("solver failed")
+	if _, _, err := cache.GetOrCalculate("post:x", func() (*rack.ReturnedValueStandard, error) { return nil, failure }); err != failure {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+	if cache.Len() != 0 {
+		t.Error("a failed calculation was cached")
+	}
+}
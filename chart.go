@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/pachev/gorack/rack"
+)
+
+// ChartRequest describes a loading chart: every weight from From to To in
+// Step increments, loaded on a bar with the given plates.
+type ChartRequest struct {
+	From      int
+	To        int
+	Step      int
+	Inventory string
+	Plates    rack.RackInputStandard // Bar weight and available plates
+}
+
+// ChartRow is the loading for a single weight in a chart.
+type ChartRow struct {
+	Weight         int       `json:"weight"`
+	AchievedWeight int       `json:"achievedWeight"`
+	Loadable       bool      `json:"loadable"`
+	PerSide        []float32 `json:"perSide"` // Plates on each side, in loading order
+}
+
+// ChartResponse is a full loading chart.
+type ChartResponse struct {
+	BarWeight int        `json:"barWeight"`
+	From      int        `json:"from"`
+	To        int        `json:"to"`
+	Step      int        `json:"step"`
+	Inventory string     `json:"inventory,omitempty"`
+	Strategy  string     `json:"strategy"`
+	Rows      []ChartRow `json:"rows"`
+}
+
+// parseChartRequest reads the chart range, bar and inventory from the query string.
+func parseChartRequest(r *http.Request) (*ChartRequest, error) {
+	query := r.URL.Query()
+	chart := &ChartRequest{Plates: rack.AssumeDefaults(), Inventory: query.Get("inventory")}
+
+	if chart.Inventory != "" {
+		inv, err := lookupInventory(r.Context(), chart.Inventory)
+		if err != nil {
+			return nil, errors.New("inventory not found")
+		}
+		chart.Plates.BarWeight = 0
+		inv.Apply(&chart.Plates)
+	}
+	if bar := query.Get("bar"); bar != "" {
+		barWeight, err := strconv.Atoi(bar)
+		if err != nil || barWeight < 0 {
+			return nil, errors.New("invalid 'bar' parameter: must be a non-negative integer")
+		}
+		chart.Plates.BarWeight = barWeight
+	}
+
+	var err error
+	if chart.Plates.Strategy, err = parseStrategy(query.Get("strategy")); err != nil {
+		return nil, err
+	}
+
+	if query.Get("to") == "" {
+		return nil, errors.New("query parameter 'to' is required")
+	}
+	if chart.From, err = queryInt(r, "from", chart.Plates.BarWeight); err != nil {
+		return nil, err
+	}
+	if chart.To, err = queryInt(r, "to", 0); err != nil {
+		return nil, err
+	}
+	if chart.Step, err = queryInt(r, "step", 5); err != nil {
+		return nil, err
+	}
+	return chart, chart.Validate()
+}
+
+// Validate checks the chart range.
+func (cr *ChartRequest) Validate() error {
+	if cr.From <= 0 {
+		return errors.New("'from' must be a positive integer")
+	}
+	if cr.To < cr.From {
+		return errors.New("'to' must be greater than or equal to 'from'")
+	}
+	if cr.To > rack.MaxSearchWeight {
+		return fmt.Errorf("'to' cannot be more than %d", rack.MaxSearchWeight)
+	}
+	if cr.Step <= 0 {
+		return errors.New("'step' must be a positive integer")
+	}
+	if maxRows := getEnvInt("CHART_MAX_ROWS", 1000); cr.rows() > maxRows {
+		return fmt.Errorf("chart cannot have more than %d rows", maxRows)
+	}
+	return nil
+}
+
+// rows is the number of weights in the chart's range.
+func (cr *ChartRequest) rows() int {
+	return (cr.To-cr.From)/cr.Step + 1
+}
+
+// BuildChart calculates the loading for every weight in the chart's range.
+// Weights the plates can't reach exactly are flagged as not loadable.
+func BuildChart(ctx context.Context, cr *ChartRequest) (*ChartResponse, error) {
+	chart := &ChartResponse{
+		BarWeight: cr.Plates.BarWeight,
+		From:      cr.From,
+		To:        cr.To,
+		Step:      cr.Step,
+		Inventory: cr.Inventory,
+		Strategy:  cr.Plates.Strategy,
+		Rows:      []ChartRow{},
+	}
+
+	for i := 0; i < cr.rows(); i++ {
+		weight := cr.From + i*cr.Step
+		row := ChartRow{Weight: weight, PerSide: []float32{}}
+		switch {
+		case weight < cr.Plates.BarWeight:
+			// Lighter than the empty bar, nothing to load
+		case weight == cr.Plates.BarWeight:
+			row.AchievedWeight = weight
+			row.Loadable = true
+		default:
+			input := cr.Plates
+			input.DesiredWeight = weight
+			result, _, err := calculateCached(ctx, generateCacheKey(&input), &input)
+			if err != nil {
+				return nil, err
+			}
+			row.AchievedWeight = result.AchievedWeight
+			row.Loadable = result.AchievedWeight == weight
+			row.PerSide = result.LoadingOrder()
+		}
+		chart.Rows = append(chart.Rows, row)
+	}
+	return chart, nil
+}
+
+// RackEmChart godoc
+// @Summary      Loading chart for a weight range
+// @Description  Returns the plate loading for every weight from 'from' to 'to' in 'step' increments, flagging weights that can't be loaded exactly. Available as JSON, CSV, a printable HTML page or a PDF.
+// @Tags         Rack
+// @Produce      json
+// @Produce      text/csv
+// @Produce      html
+// @Produce      application/pdf
+// @Param        from       query     int     false  "First weight (defaults to the bar weight)"
+// @Param        to         query     int     true   "Last weight"
+// @Param        step       query     int     false  "Increment between weights (default 5)"
+// @Param        bar        query     int     false  "Bar weight (defaults to the inventory's bar or 45)"
+// @Param        inventory  query     string  false  "Inventory profile ID to use instead of the default plates"
+// @Param        strategy   query     string  false  "Solver strategy (default greedy)"
+// @Param        format     query     string  false  "json (default), csv, html or pdf"
+// @Success      200  {object}  ChartResponse
+// @Failure      400  {object}  ErrResponse
+// @Failure      429  {object}  ErrResponse
+// @Failure      500  {object}  ErrResponse
+// @Router       /rack/chart [get]
+func RackEmChart(w http.ResponseWriter, r *http.Request) {
+	request, err := parseChartRequest(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "html" && format != "pdf" {
+		render.Render(w, r, ErrInvalidRequest(errors.New("'format' must be json, csv, html or pdf")))
+		return
+	}
+	// Limit took one token for the request; each row after the first costs another
+	if rows := request.rows(); rows > 1 && !rateLimiter.Charge(w, r, rows-1) {
+		return
+	}
+
+	chart, err := BuildChart(r.Context(), request)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Building chart failed", "err", err, "request", request)
+		render.Render(w, r, ErrCalculation(err))
+		return
+	}
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="loading-chart-%d-%d.csv"`, chart.From, chart.To))
+		if err := writeChartCSV(w, chart); err != nil {
+			slog.ErrorContext(r.Context(), "Writing chart CSV failed", "err", err)
+		}
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := chartTemplate.Execute(w, chart); err != nil {
+			slog.ErrorContext(r.Context(), "Rendering chart HTML failed", "err", err)
+		}
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="loading-chart-%d-%d.pdf"`, chart.From, chart.To))
+		if err := WriteChartPDF(w, chart); err != nil {
+			slog.ErrorContext(r.Context(), "Writing chart PDF failed", "err", err)
+		}
+	default:
+		render.JSON(w, r, chart)
+	}
+}
+
+// writeChartCSV writes one line per weight with the per-side plates space separated.
+func writeChartCSV(w http.ResponseWriter, chart *ChartResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"weight", "achieved_weight", "loadable", "per_side"}); err != nil {
+		return err
+	}
+	for _, row := range chart.Rows {
+		record := []string{
+			strconv.Itoa(row.Weight),
+			strconv.Itoa(row.AchievedWeight),
+			strconv.FormatBool(row.Loadable),
+			formatPlates(row.PerSide, " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatPlates renders plate weights without trailing zeros (45, 2.5, 1.25).
+func formatPlates(plates []float32, sep string) string {
+	formatted := make([]string, len(plates))
+	for i, plate := range plates {
+		formatted[i] = strconv.FormatFloat(float64(plate), 'f', -1, 32)
+	}
+	return strings.Join(formatted, sep)
+}
+
+// chartTemplate is the printable wall chart.
+var chartTemplate = template.Must(template.New("chart").Funcs(template.FuncMap{
+	"plates": func(plates []float32) string { return formatPlates(plates, " · ") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Loading chart {{.From}}–{{.To}} lb</title>
+<style>
+  body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; margin: 2rem; }
+  h1 { font-size: 1.4rem; margin-bottom: 0.2rem; }
+  p.meta { color: #555; margin-top: 0; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { border: 1px solid #ccc; padding: 0.25rem 0.5rem; text-align: left; }
+  th { background: #f2f2f2; }
+  td.weight { font-weight: bold; width: 6rem; }
+  tr.unloadable td { color: #a00; background: #fff3f3; }
+  @media print {
+    body { margin: 0.5cm; }
+    tr { page-break-inside: avoid; }
+  }
+</style>
+</head>
+<body>
+<h1>Loading chart {{.From}}–{{.To}} lb</h1>
+<p class="meta">{{.BarWeight}} lb bar · {{.Step}} lb steps · {{.Strategy}} strategy · plates listed per side, in loading order</p>
+<table>
+  <thead><tr><th>Weight</th><th>Per side</th></tr></thead>
+  <tbody>
+  {{- range .Rows}}
+    {{- if .Loadable}}
+    <tr><td class="weight">{{.Weight}}</td><td>{{if .PerSide}}{{plates .PerSide}}{{else}}Empty bar{{end}}</td></tr>
+    {{- else}}
+    <tr class="unloadable"><td class="weight">{{.Weight}}</td><td>Can't load{{if .AchievedWeight}} – closest is {{.AchievedWeight}}: {{plates .PerSide}}{{end}}</td></tr>
+    {{- end}}
+  {{- end}}
+  </tbody>
+</table>
+</body>
+</html>
+`))
+
+// queryInt reads an integer query parameter, returning fallback when it is absent.
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '%s' parameter: must be an integer", name)
+	}
+	return i, nil
+}
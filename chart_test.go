@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pachev/gorack/rack"
+)
+
+func TestChartRequestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		from, to, step int
+		error          string
+	}{
+		{"single row", 45, 45, 5, ""},
+		{"range", 45, 315, 5, ""},
+		{"largest", rack.MaxSearchWeight - 995, rack.MaxSearchWeight, 1, ""},
+		{"zero from", 0, 100, 5, "'from' must be a positive integer"},
+		{"backwards", 100, 50, 5, "'to' must be greater than or equal to 'from'"},
+		{"zero step", 45, 100, 0, "'step' must be a positive integer"},
+		{"too many rows", 45, 5000, 1, "chart cannot have more than 1000 rows"},
+		{"too heavy", rack.MaxSearchWeight, rack.MaxSearchWeight + 1, 1, "'to' cannot be more than 20000"},
+		// Used to pass, then wrap around while building and never return
+		{"near MaxInt", 9223372036854775806, 9223372036854775807, 1, "'to' cannot be more than 20000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ChartRequest{From: tt.from, To: tt.to, Step: tt.step}).Validate()
+			switch {
+			case tt.error == "" && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.error != "" && (err == nil || err.Error() != tt.error):
+				t.Errorf("Validate() = %v, want %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestBuildChart(t *testing.T) {
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	request := &ChartRequest{From: 40, To: 60, Step: 4, Plates: rack.AssumeDefaults()}
+	chart, err := BuildChart(context.Background(), request)
+	if err != nil {
+		t.Fatalf("BuildChart: %v", err)
+	}
+
+	want := []ChartRow{
+		{Weight: 40},
+		{Weight: 44},
+		{Weight: 48, AchievedWeight: 45},
+		{Weight: 52, AchievedWeight: 50, PerSide: []float32{2.5}},
+		{Weight: 56, AchievedWeight: 55, PerSide: []float32{5}},
+		{Weight: 60, AchievedWeight: 60, Loadable: true, PerSide: []float32{5, 2.5}},
+	}
+	if len(chart.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(chart.Rows), len(want), chart.Rows)
+	}
+	for i, row := range chart.Rows {
+		if row.Weight != want[i].Weight || row.AchievedWeight != want[i].AchievedWeight ||
+			row.Loadable != want[i].Loadable ||
+			formatPlates(row.PerSide, " ") != formatPlates(want[i].PerSide, " ") {
+			t.Errorf("row %d = %+v, want %+v", i, row, want[i])
+		}
+	}
+}
+
+func TestRackEmChartRejectsOverflow(t *testing.T) {
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	req := httptest.NewRequest("GET", "/v1/api/rack/chart?from=9223372036854775806&to=9223372036854775807&step=1", nil)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		RackEmChart(rec, req)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RackEmChart didn't return")
+	}
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "'to' cannot be more than") {
+		t.Errorf("got %d %s, want 400 for 'to'", rec.Code, rec.Body)
+	}
+}
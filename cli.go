@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pachev/gorack/rack"
+)
+
+const cliUsage = `Usage: gorack [command] [arguments]
+
+Commands:
+  serve                        Start the HTTP API server (default)
+  calc <weight> [flags]        Calculate the plates for one weight
+  chart <from>..<to>[/step]    Calculate the plates for a range of weights
+  help                         Show this help
+
+Flags for calc and chart:
+  --bar <weight>               Bar weight (default: the inventory's bar or 45)
+  --inventory <file>           YAML or JSON file listing available plate pairs
+  --format <table|json|ascii>  Output format (default: table)
+  --strategy <name>            Solver strategy (default: greedy)
+  --explain                    Show the solver's decisions (calc only)
+
+Examples:
+  gorack calc 225 --bar 45 --inventory garage.yaml
+  gorack chart 135..405/10 --format ascii
+`
+
+// InventoryFile is the on-disk inventory format used by the CLI. It uses the
+// same field names as the API, e.g.:
+//
+//	barWeight: 45
+//	fortyFives: 4
+//	tens: 2
+type InventoryFile struct {
+	BarWeight      int `yaml:"barWeight"`
+	Hundos         int `yaml:"hundreds"`
+	FortyFives     int `yaml:"fortyFives"`
+	ThirtyFives    int `yaml:"thirtyFives"`
+	TwentyFives    int `yaml:"twentyFives"`
+	Tens           int `yaml:"tens"`
+	Fives          int `yaml:"fives"`
+	TwoDotFives    int `yaml:"twoDotFives"`
+	OneDotTwoFives int `yaml:"oneDotTwoFives"`
+}
+
+// cliOptions are the flags shared by calc and chart.
+type cliOptions struct {
+	bar       int
+	inventory string
+	format    string
+	strategy  string
+	explain   bool
+}
+
+// runCLI runs a command-line command and returns the process exit code.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	var err error
+	switch args[0] {
+	case "serve":
+		serve()
+		return 0
+	case "calc":
+		err = runCalc(args[1:], stdout)
+	case "chart":
+		err = runChart(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gorack: unknown command %q\n\n%s", args[0], cliUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gorack %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// runCalc implements `gorack calc <weight>`.
+func runCalc(args []string, stdout io.Writer) error {
+	options, positional, err := parseCLIFlags("calc", args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one weight, e.g. `gorack calc 225`")
+	}
+	weight, err := strconv.Atoi(positional[0])
+	if err != nil || weight <= 0 {
+		return fmt.Errorf("invalid weight %q: must be a positive integer", positional[0])
+	}
+
+	input, err := options.plates()
+	if err != nil {
+		return err
+	}
+	input.DesiredWeight = weight
+	if input.DesiredWeight <= input.BarWeight {
+		return errors.New("desired weight must be greater than bar weight")
+	}
+
+	calculate := rack.CalculateWeight
+	if options.explain {
+		calculate = rack.Explain
+	}
+	result, err := calculate(&input)
+	if err != nil {
+		return err
+	}
+
+	switch options.format {
+	case "json":
+		return writeJSON(stdout, result)
+	case "ascii":
+		fmt.Fprintln(stdout, asciiBar(result.LoadingOrder(), result.BarWeight))
+		if result.AchievedWeight != weight {
+			fmt.Fprintf(stdout, "Can't load %d lb exactly, closest is %d lb\n", weight, result.AchievedWeight)
+		}
+		return writeExplanation(stdout, result.Explanation)
+	default:
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Target\t%d lb\n", weight)
+		fmt.Fprintf(tw, "Achieved\t%d lb\n", result.AchievedWeight)
+		fmt.Fprintf(tw, "Bar\t%d lb\n", result.BarWeight)
+		fmt.Fprintf(tw, "Strategy\t%s\n", result.Strategy)
+		fmt.Fprintf(tw, "Per side\t%s\n", orDash(formatPlates(result.LoadingOrder(), " ")))
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Plate\tPairs")
+		for _, plateName := range rack.PlateOrder {
+			if count := result.PlateCount(plateName); count > 0 {
+				fmt.Fprintf(tw, "%s\t%d\n", formatPlates([]float32{rack.WeightAmounts[plateName]}, ""), count)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		return writeExplanation(stdout, result.Explanation)
+	}
+}
+
+// writeExplanation prints a decision trace, one step per line. It prints
+// nothing when there is no trace.
+func writeExplanation(w io.Writer, explanation *rack.Explanation) error {
+	if explanation == nil {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Plate\tDecision\tRemaining")
+	for _, step := range explanation.Steps {
+		decision := fmt.Sprintf("load %d pair(s)", step.Pairs)
+		if step.Skipped != "" {
+			decision = "skip: " + step.Skipped
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d lb\n", formatPlates([]float32{step.Plate}, ""), decision, step.Remaining)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if explanation.Note != "" {
+		fmt.Fprintf(w, "\n%s\n", explanation.Note)
+	}
+	return nil
+}
+
+// runChart implements `gorack chart <from>..<to>[/step]`.
+func runChart(args []string, stdout io.Writer) error {
+	options, positional, err := parseCLIFlags("chart", args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one range, e.g. `gorack chart 135..405/10`")
+	}
+
+	plates, err := options.plates()
+	if err != nil {
+		return err
+	}
+	request := &ChartRequest{Plates: plates}
+	if request.From, request.To, request.Step, err = parseRange(positional[0]); err != nil {
+		return err
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	// Results only need to live as long as the command
+	weightCache = NewWeightCache(CacheConfig{})
+	chart, err := BuildChart(context.Background(), request)
+	if err != nil {
+		return err
+	}
+
+	switch options.format {
+	case "json":
+		return writeJSON(stdout, chart)
+	case "ascii":
+		for _, row := range chart.Rows {
+			line := asciiBar(row.PerSide, chart.BarWeight)
+			if !row.Loadable {
+				line = fmt.Sprintf("can't load (closest %d)", row.AchievedWeight)
+			}
+			fmt.Fprintf(stdout, "%5d  %s\n", row.Weight, line)
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Weight\tPer side")
+		for _, row := range chart.Rows {
+			perSide := orDash(formatPlates(row.PerSide, " "))
+			if !row.Loadable {
+				perSide = fmt.Sprintf("can't load (closest %d: %s)", row.AchievedWeight, perSide)
+			}
+			fmt.Fprintf(tw, "%d\t%s\n", row.Weight, perSide)
+		}
+		return tw.Flush()
+	}
+}
+
+// parseCLIFlags parses the shared flags, allowing them before or after the
+// positional arguments (`calc 225 --bar 35` and `calc --bar 35 225`).
+func parseCLIFlags(command string, args []string) (*cliOptions, []string, error) {
+	options := &cliOptions{}
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.IntVar(&options.bar, "bar", 0, "bar weight")
+	fs.StringVar(&options.inventory, "inventory", "", "inventory file")
+	fs.StringVar(&options.format, "format", "table", "output format")
+	fs.StringVar(&options.strategy, "strategy", rack.DefaultStrategy, "solver strategy")
+	fs.BoolVar(&options.explain, "explain", false, "show the solver's decisions")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if options.bar < 0 {
+		return nil, nil, errors.New("bar weight cannot be negative")
+	}
+	switch options.format {
+	case "table", "json", "ascii":
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q: must be table, json or ascii", options.format)
+	}
+	if _, err := rack.Lookup(options.strategy); err != nil {
+		return nil, nil, err
+	}
+	return options, positional, nil
+}
+
+// plates returns the bar and available plates selected by the flags. Without
+// an inventory file the server's default plates are used.
+func (o *cliOptions) plates() (rack.RackInputStandard, error) {
+	plates := rack.AssumeDefaults()
+	if o.inventory != "" {
+		inv, err := LoadInventoryFile(o.inventory)
+		if err != nil {
+			return plates, err
+		}
+		plates = rack.RackInputStandard{
+			BarWeight:      inv.BarWeight,
+			Hundos:         inv.Hundos,
+			FortyFives:     inv.FortyFives,
+			ThirtyFives:    inv.ThirtyFives,
+			TwentyFives:    inv.TwentyFives,
+			Tens:           inv.Tens,
+			Fives:          inv.Fives,
+			TwoDotFives:    inv.TwoDotFives,
+			OneDotTwoFives: inv.OneDotTwoFives,
+		}
+		if plates.BarWeight == 0 {
+			plates.BarWeight = rack.AssumeDefaults().BarWeight
+		}
+	}
+	if o.bar > 0 {
+		plates.BarWeight = o.bar
+	}
+	plates.Strategy = o.strategy
+	return plates, nil
+}
+
+// LoadInventoryFile reads an inventory from a YAML (or JSON) file.
+func LoadInventoryFile(path string) (*InventoryFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inv := &InventoryFile{}
+	if err := yaml.Unmarshal(raw, inv); err != nil {
+		return nil, fmt.Errorf("reading inventory %s: %w", path, err)
+	}
+	return inv, nil
+}
+
+// parseRange parses "from..to" or "from..to/step". The step defaults to 5.
+func parseRange(value string) (from, to, step int, err error) {
+	invalid := fmt.Errorf("invalid range %q: expected <from>..<to>[/step], e.g. 135..405/10", value)
+
+	bounds, stepStr, hasStep := strings.Cut(value, "/")
+	fromStr, toStr, ok := strings.Cut(bounds, "..")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+	if from, err = strconv.Atoi(fromStr); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if to, err = strconv.Atoi(toStr); err != nil {
+		return 0, 0, 0, invalid
+	}
+	step = 5
+	if hasStep {
+		if step, err = strconv.Atoi(stepStr); err != nil {
+			return 0, 0, 0, invalid
+		}
+	}
+	return from, to, step, nil
+}
+
+// asciiBar draws a loaded bar, outermost plates at the ends:
+//
+//	--|10|45|45|====( 45 )====|45|45|10|--
+func asciiBar(perSide []float32, barWeight int) string {
+	labels := strings.Fields(formatPlates(perSide, " "))
+	right := ""
+	for _, label := range labels {
+		right += "|" + label
+	}
+	left := ""
+	for i := len(labels) - 1; i >= 0; i-- {
+		left += labels[i] + "|"
+	}
+	if len(labels) > 0 {
+		left = "|" + left
+		right += "|"
+	}
+	return fmt.Sprintf("--%s====( %d )====%s--", left, barWeight, right)
+}
+
+// writeJSON prints an indented JSON document.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// orDash substitutes a dash for an empty table cell.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
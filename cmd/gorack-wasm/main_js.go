@@ -0,0 +1,50 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// main installs globalThis.gorack and keeps the module alive for calls:
+//
+//	gorack.rack(225)                                  // like GET /rack?weight=225
+//	gorack.rack({weight: 225, strategy: "exact"})     // GET options
+//	gorack.rack({desiredWeight: 185, fortyFives: 2})  // a POST /rack body
+//	gorack.strategies()                               // strategy names
+//	gorack.bars()                                     // the bar catalog
+//
+// Every function returns a JSON string. rack returns the server's error JSON
+// for bad requests, so callers can handle both the same way.
+func main() {
+	js.Global().Set("gorack", js.ValueOf(map[string]any{
+		"rack":       js.FuncOf(jsRack),
+		"strategies": js.FuncOf(func(js.Value, []js.Value) any { return marshal(rack.Strategies()) }),
+		"bars":       js.FuncOf(func(js.Value, []js.Value) any { return marshal(rack.Bars) }),
+	}))
+	select {}
+}
+
+func jsRack(_ js.Value, args []js.Value) any {
+	var body string
+	switch {
+	case len(args) == 0:
+		body = "{}"
+	case args[0].Type() == js.TypeNumber:
+		body = marshal(map[string]int{"weight": args[0].Int()})
+	case args[0].Type() == js.TypeString:
+		body = args[0].String()
+	default:
+		body = js.Global().Get("JSON").Call("stringify", args[0]).String()
+	}
+	out, _ := handle([]byte(body))
+	return string(out)
+}
+
+func marshal(v any) string {
+	out, _ := json.Marshal(v)
+	return string(out)
+}
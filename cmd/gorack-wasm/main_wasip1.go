@@ -0,0 +1,25 @@
+//go:build wasip1
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// main reads one request from stdin and writes the response to stdout,
+// exiting with status 1 when the response is an error:
+//
+//	echo '{"weight": 225}' | wasmtime gorack.wasm
+func main() {
+	body, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		os.Stderr.WriteString("reading request: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+	out, ok := handle(body)
+	os.Stdout.Write(append(out, '\n'))
+	if !ok {
+		os.Exit(1)
+	}
+}
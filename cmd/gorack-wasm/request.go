@@ -0,0 +1,89 @@
+//go:build js || wasip1
+
+// Command gorack-wasm is the plate solver compiled to WebAssembly for clients
+// that need to work offline. It answers the same requests as /v1/api/rack with
+// the same JSON, without a server.
+//
+// Build it for browsers and Node with GOOS=js GOARCH=wasm, which exposes a
+// global gorack object, or for WASI runtimes with GOOS=wasip1 GOARCH=wasm,
+// which reads one request from stdin and writes the response to stdout.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// request is either form of /v1/api/rack. With Weight it follows GET /rack
+// and uses the default plates; otherwise it's a POST /rack body.
+type request struct {
+	rack.RackInputStandard
+	Weight  int  `json:"weight,omitempty"`
+	Explain bool `json:"explain,omitempty"`
+}
+
+// errResponse matches the server's error JSON.
+type errResponse struct {
+	StatusText string `json:"status"`
+	ErrorText  string `json:"error,omitempty"`
+}
+
+// handle runs a JSON request and returns the JSON the server would have
+// sent, and whether it was a success.
+func handle(body []byte) ([]byte, bool) {
+	result, errResp := calculate(body)
+	if errResp != nil {
+		out, _ := json.Marshal(errResp)
+		return out, false
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		out, _ = json.Marshal(errInternal(err))
+		return out, false
+	}
+	return out, true
+}
+
+// calculate validates and solves a request like the server's rack handlers.
+func calculate(body []byte) (*rack.ReturnedValueStandard, *errResponse) {
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errInvalidRequest(errors.New("invalid request body: " + err.Error()))
+	}
+
+	input := req.RackInputStandard
+	if req.Weight != 0 {
+		if req.Weight < 0 {
+			return nil, errInvalidRequest(errors.New("'weight' must be a positive integer"))
+		}
+		input = rack.AssumeDefaults()
+		input.DesiredWeight = req.Weight
+		input.Strategy = req.Strategy
+	}
+	if err := input.Validate(); err != nil {
+		return nil, errInvalidRequest(err)
+	}
+
+	solve := rack.CalculateWeight
+	if req.Explain {
+		solve = rack.Explain
+	}
+	result, err := solve(&input)
+	if errors.Is(err, rack.ErrSearchTooLarge) {
+		return nil, errInvalidRequest(err)
+	}
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	return result, nil
+}
+
+func errInvalidRequest(err error) *errResponse {
+	return &errResponse{StatusText: "Invalid request.", ErrorText: err.Error()}
+}
+
+func errInternal(err error) *errResponse {
+	return &errResponse{StatusText: "Internal Server Error.", ErrorText: err.Error()}
+}
@@ -24,6 +24,144 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/cache": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Returns the cache's size, limits, hit and miss counts, evictions and the age of its oldest entry",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Show result cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.CacheStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Removes every cached result, or only those whose key starts with prefix: \"get:\" for GET /rack with the default plates, \"post:\" for custom plates and inventories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Clear cached results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only clear keys with this prefix, such as get: or post:",
+                        "name": "prefix",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ClearCacheResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Lists each API key's rate-limited requests so far today (UTC), busiest first, with the daily quota when DAILY_QUOTA is set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Show today's requests per API key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.KeyUsage"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
                 "description": "Returns status of the API server",
@@ -47,9 +185,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/rack": {
+        "/inventories": {
             "get": {
-                "description": "Returns an optimal plate configuration for a given target weight",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the caller's saved inventory profiles ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "List inventory profiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Inventory"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves a named set of available plates and a bar weight",
                 "consumes": [
                     "application/json"
                 ],
@@ -57,23 +238,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rack"
+                    "Inventory"
                 ],
-                "summary": "Calculate plates using default plate availability",
+                "summary": "Create an inventory profile",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Desired weight in pounds",
-                        "name": "weight",
-                        "in": "query",
-                        "required": true
+                        "description": "Inventory name, bar weight and available plates",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.InventoryRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/main.ReturnedValueStandard"
+                            "$ref": "#/definitions/main.Inventory"
                         }
                     },
                     "400": {
@@ -82,6 +265,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -89,9 +284,65 @@ const docTemplate = `{
                         }
                     }
                 }
+            }
+        },
+        "/inventories/{inventoryID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Get an inventory profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inventory ID",
+                        "name": "inventoryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Inventory"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
             },
-            "post": {
-                "description": "Returns an optimal plate configuration for a given target weight with custom available plates",
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the name, bar weight and plates of an inventory profile",
                 "consumes": [
                     "application/json"
                 ],
@@ -99,17 +350,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rack"
+                    "Inventory"
                 ],
-                "summary": "Calculate plates with custom plate availability",
+                "summary": "Update an inventory profile",
                 "parameters": [
                     {
-                        "description": "Desired weight and available plates",
+                        "type": "string",
+                        "description": "Inventory ID",
+                        "name": "inventoryID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Inventory name, bar weight and available plates",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.RackInputStandard"
+                            "$ref": "#/definitions/main.InventoryRequest"
                         }
                     }
                 ],
@@ -117,7 +375,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ReturnedValueStandard"
+                            "$ref": "#/definitions/main.Inventory"
                         }
                     },
                     "400": {
@@ -126,6 +384,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -133,56 +409,1222 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/status": {
-            "get": {
-                "description": "Returns status of the API server",
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
                 "tags": [
-                    "Health"
+                    "Inventory"
+                ],
+                "summary": "Delete an inventory profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inventory ID",
+                        "name": "inventoryID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health check endpoint",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
                         }
                     }
                 }
             }
         },
-        "/v1/api/health": {
+        "/keys": {
             "get": {
-                "description": "Returns status of the API server",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns every key on the account, including revoked ones",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Auth"
                 ],
-                "summary": "Health check endpoint",
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.APIKeyResponse"
                             }
                         }
-                    }
-                }
-            }
-        }
-    },
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issues a new key with a subset of the current key's scopes. The key is only shown once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Key name and scopes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{keyID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{keyID}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the key and issues a replacement with the same name and scopes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Rotate an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIKeyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rack": {
+            "get": {
+                "description": "Returns an optimal plate configuration for a given target weight. The response format follows the Accept header: JSON, XML, YAML or MessagePack.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Rack"
+                ],
+                "summary": "Calculate plates using default plate availability",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Desired weight in pounds",
+                        "name": "weight",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inventory profile ID to use instead of the default plates",
+                        "name": "inventory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Solver strategy: greedy (default), exact, fewest-plates, fewest-small-plates or keep-big-free",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the solver's decision trace",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy; a match returns 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rack.ReturnedValueStandard"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "public with max-age CACHE_TTL for the default plates, private, no-cache with an inventory"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong validator for this result in this format"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy named in If-None-Match is still current"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Returns an optimal plate configuration for a given target weight with custom available plates. The body and response can be JSON, XML, YAML or MessagePack (see Content-Type and Accept).",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Rack"
+                ],
+                "summary": "Calculate plates with custom plate availability",
+                "parameters": [
+                    {
+                        "description": "Desired weight and available plates",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RackRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Solver strategy, used when the body doesn't name one",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the solver's decision trace",
+                        "name": "explain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rack.ReturnedValueStandard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rack/batch": {
+            "post": {
+                "description": "Takes an array of rack requests, each with its own bar, plates or inventory, and returns results in the same order. Invalid items get a per-item error instead of failing the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rack"
+                ],
+                "summary": "Calculate plates for many requests at once",
+                "parameters": [
+                    {
+                        "description": "Rack requests",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.RackRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Solver strategy for items that don't name one",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include every item's decision trace",
+                        "name": "explain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.BatchRackResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rack/chart": {
+            "get": {
+                "description": "Returns the plate loading for every weight from 'from' to 'to' in 'step' increments, flagging weights that can't be loaded exactly. Available as JSON, CSV, a printable HTML page or a PDF.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/html",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Rack"
+                ],
+                "summary": "Loading chart for a weight range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "First weight (defaults to the bar weight)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last weight",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Increment between weights (default 5)",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Bar weight (defaults to the inventory's bar or 45)",
+                        "name": "bar",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inventory profile ID to use instead of the default plates",
+                        "name": "inventory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Solver strategy (default greedy)",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default), csv, html or pdf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ChartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/pdf": {
+            "post": {
+                "description": "Renders a planned session as a PDF with target weights, per-side plates and bar diagrams",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Rack"
+                ],
+                "summary": "Printable session sheet",
+                "parameters": [
+                    {
+                        "description": "Planned session",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SessionSheetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Returns status of the API server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "description": "Creates a user and returns its first API key with every scope. The key is only shown once. Needs the admin key unless ALLOW_SIGNUP is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Create an account",
+                "parameters": [
+                    {
+                        "description": "Account name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SignupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the current account",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/api/health": {
+            "get": {
+                "description": "Returns status of the API server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns logged sessions, oldest first. Filtering by exercise keeps only that lift's sets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Query workout history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exercise name (case-insensitive)",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date, inclusive (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date, inclusive for plain dates (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Workout"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stores the sets of a session together with the plate loading for each set's weight",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Log a workout session",
+                "parameters": [
+                    {
+                        "description": "Session date, plates and sets",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.WorkoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.Workout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/trends": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the best Epley estimated one-rep max per day for each lift",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Estimated one-rep max trends",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exercise name (case-insensitive)",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date, inclusive (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date, inclusive for plain dates (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.ExerciseTrend"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{workoutID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Get a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workout ID",
+                        "name": "workoutID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Workout"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Delete a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workout ID",
+                        "name": "workoutID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
+        "main.APIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.BatchRackResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/main.ErrResponse"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "result": {
+                    "$ref": "#/definitions/rack.ReturnedValueStandard"
+                }
+            }
+        },
+        "main.CacheStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "description": "Approximate, see entrySize",
+                    "type": "integer"
+                },
+                "calculations": {
+                    "description": "Solver runs after a miss",
+                    "type": "integer"
+                },
+                "coalesced": {
+                    "description": "Misses that waited for an identical calculation instead",
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "evictions": {
+                    "description": "Removed to stay within the limits",
+                    "type": "integer"
+                },
+                "expirations": {
+                    "description": "Removed after their TTL",
+                    "type": "integer"
+                },
+                "hitRate": {
+                    "description": "Hits over lookups, 0 before the first lookup",
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "maxBytes": {
+                    "type": "integer"
+                },
+                "maxEntries": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "oldestEntrySeconds": {
+                    "type": "number"
+                },
+                "tableHits": {
+                    "description": "Hits answered from the loading table, included in Hits",
+                    "type": "integer"
+                },
+                "ttlSeconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.ChartResponse": {
+            "type": "object",
+            "properties": {
+                "barWeight": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "inventory": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ChartRow"
+                    }
+                },
+                "step": {
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ChartRow": {
+            "type": "object",
+            "properties": {
+                "achievedWeight": {
+                    "type": "integer"
+                },
+                "loadable": {
+                    "type": "boolean"
+                },
+                "perSide": {
+                    "description": "Plates on each side, in loading order",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ClearCacheResponse": {
+            "type": "object",
+            "properties": {
+                "prefix": {
+                    "type": "string"
+                },
+                "removed": {
+                    "type": "integer"
+                }
+            }
+        },
         "main.ErrResponse": {
             "type": "object",
             "properties": {
@@ -200,7 +1642,142 @@ const docTemplate = `{
                 }
             }
         },
-        "main.RackInputStandard": {
+        "main.ExerciseTrend": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.TrendPoint"
+                    }
+                }
+            }
+        },
+        "main.Inventory": {
+            "type": "object",
+            "properties": {
+                "barWeight": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fives": {
+                    "type": "integer"
+                },
+                "fortyFives": {
+                    "type": "integer"
+                },
+                "hundreds": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "oneDotTwoFives": {
+                    "type": "integer"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "tens": {
+                    "type": "integer"
+                },
+                "thirtyFives": {
+                    "type": "integer"
+                },
+                "twentyFives": {
+                    "type": "integer"
+                },
+                "twoDotFives": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.InventoryRequest": {
+            "type": "object",
+            "properties": {
+                "barWeight": {
+                    "type": "integer"
+                },
+                "fives": {
+                    "type": "integer"
+                },
+                "fortyFives": {
+                    "type": "integer"
+                },
+                "hundreds": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "oneDotTwoFives": {
+                    "type": "integer"
+                },
+                "tens": {
+                    "type": "integer"
+                },
+                "thirtyFives": {
+                    "type": "integer"
+                },
+                "twentyFives": {
+                    "type": "integer"
+                },
+                "twoDotFives": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.KeyUsage": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "UTC, as YYYY-MM-DD",
+                    "type": "string"
+                },
+                "keyId": {
+                    "type": "string"
+                },
+                "quota": {
+                    "description": "0 when there's no daily quota",
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.PlannedSet": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "reps": {
+                    "type": "integer"
+                },
+                "sets": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.RackRequest": {
             "type": "object",
             "properties": {
                 "barWeight": {
@@ -210,6 +1787,10 @@ const docTemplate = `{
                     "description": "Required in input",
                     "type": "integer"
                 },
+                "explain": {
+                    "description": "Include the solver's decision trace",
+                    "type": "boolean"
+                },
                 "fives": {
                     "type": "integer"
                 },
@@ -220,9 +1801,17 @@ const docTemplate = `{
                     "description": "JSON tag \"hundreds\" for API compatibility",
                     "type": "integer"
                 },
+                "inventory": {
+                    "description": "Optional inventory profile ID, replaces listed plates",
+                    "type": "string"
+                },
                 "oneDotTwoFives": {
                     "type": "integer"
                 },
+                "strategy": {
+                    "description": "Solver strategy, defaults to DefaultStrategy",
+                    "type": "string"
+                },
                 "tens": {
                     "type": "integer"
                 },
@@ -237,7 +1826,182 @@ const docTemplate = `{
                 }
             }
         },
-        "main.ReturnedValueStandard": {
+        "main.SessionSheetRequest": {
+            "type": "object",
+            "properties": {
+                "athlete": {
+                    "type": "string"
+                },
+                "barWeight": {
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "YYYY-MM-DD, defaults to today",
+                    "type": "string"
+                },
+                "inventory": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "sets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.PlannedSet"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SignupResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/main.APIKeyResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/main.User"
+                }
+            }
+        },
+        "main.TrendPoint": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "estimatedOneRepMax": {
+                    "type": "number"
+                },
+                "reps": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.User": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.UserRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.Workout": {
+            "type": "object",
+            "properties": {
+                "barWeight": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inventory": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "performedAt": {
+                    "type": "string"
+                },
+                "sets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.WorkoutSet"
+                    }
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.WorkoutRequest": {
+            "type": "object",
+            "properties": {
+                "barWeight": {
+                    "type": "integer"
+                },
+                "inventory": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "performedAt": {
+                    "description": "YYYY-MM-DD or RFC 3339, defaults to now",
+                    "type": "string"
+                },
+                "sets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.WorkoutSet"
+                    }
+                }
+            }
+        },
+        "main.WorkoutSet": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "string"
+                },
+                "loading": {
+                    "$ref": "#/definitions/rack.ReturnedValueStandard"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "reps": {
+                    "type": "integer"
+                },
+                "rpe": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rack.Explanation": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "Why this result beat the alternatives",
+                    "type": "string"
+                },
+                "steps": {
+                    "description": "Plates considered, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rack.Step"
+                    }
+                }
+            }
+        },
+        "rack.ReturnedValueStandard": {
             "type": "object",
             "properties": {
                 "achievedWeight": {
@@ -250,6 +2014,14 @@ const docTemplate = `{
                     "description": "Required in input",
                     "type": "integer"
                 },
+                "explanation": {
+                    "description": "Only set by Explain",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/rack.Explanation"
+                        }
+                    ]
+                },
                 "fives": {
                     "type": "integer"
                 },
@@ -266,6 +2038,10 @@ const docTemplate = `{
                 "oneDotTwoFives": {
                     "type": "integer"
                 },
+                "strategy": {
+                    "description": "Solver strategy, defaults to DefaultStrategy",
+                    "type": "string"
+                },
                 "tens": {
                     "type": "integer"
                 },
@@ -279,12 +2055,63 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "rack.Step": {
+            "type": "object",
+            "properties": {
+                "pairs": {
+                    "description": "Pairs loaded, 0 when skipped",
+                    "type": "integer"
+                },
+                "plate": {
+                    "description": "Weight of a single plate",
+                    "type": "number"
+                },
+                "remaining": {
+                    "description": "Weight still to load after this step",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Why the plate wasn't loaded",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminKey": {
+            "description": "The server's ADMIN_API_KEY",
+            "type": "apiKey",
+            "name": "X-Admin-Key",
+            "in": "header"
+        },
+        "ApiKeyAuth": {
+            "description": "API key as \"Bearer grk_...\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "tags": [
         {
             "description": "Operations for calculating barbell weight plates",
             "name": "Rack"
+        },
+        {
+            "description": "Saved plate inventory profiles",
+            "name": "Inventory"
+        },
+        {
+            "description": "User accounts and API keys",
+            "name": "Auth"
+        },
+        {
+            "description": "Workout logging and estimated one-rep max trends",
+            "name": "Workouts"
+        },
+        {
+            "description": "Operator endpoints for the result cache",
+            "name": "Admin"
         }
     ]
 }`
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/go-chi/render"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/pachev/gorack/rack"
+)
+
+// GraphQLRequest is a GraphQL operation, sent as a JSON body or as query parameters.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// rackResult is a calculation result with the query that produced it, so
+// alternatives can be worked out when a client asks for them.
+type rackResult struct {
+	*rack.ReturnedValueStandard
+	query RackQuery
+}
+
+// platePairs is one plate size in a result.
+type platePairs struct {
+	Name   string  `json:"name"`
+	Weight float32 `json:"weight"`
+	Pairs  int     `json:"pairs"`
+}
+
+var platesInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "PlatesInput",
+	Description: "Available plates, as PAIRS",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"barWeight":      &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"hundreds":       &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"fortyFives":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"thirtyFives":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"twentyFives":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"tens":           &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"fives":          &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"twoDotFives":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"oneDotTwoFives": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+	},
+})
+
+var barType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Bar",
+	Fields: graphql.Fields{
+		"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"weight": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+var platePairsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PlatePairs",
+	Fields: graphql.Fields{
+		"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"weight": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Weight of a single plate"},
+		"pairs":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+var stepType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Step",
+	Fields: graphql.Fields{
+		"plate": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"pairs": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"skipped": &graphql.Field{
+			Type:        graphql.String,
+			Description: "Why the plate wasn't loaded, null when it was",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if skipped := p.Source.(rack.Step).Skipped; skipped != "" {
+					return skipped, nil
+				}
+				return nil, nil
+			},
+		},
+		"remaining": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+var explanationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Explanation",
+	Fields: graphql.Fields{
+		"steps": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(stepType))},
+		"note": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if note := p.Source.(*rack.Explanation).Note; note != "" {
+					return note, nil
+				}
+				return nil, nil
+			},
+		},
+	},
+})
+
+var rackResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "RackResult",
+	Description: "How to load the bar, the same as the REST /rack response",
+	Fields: graphql.Fields{
+		"barWeight":      resultField(graphql.Int, func(rv *rackResult) any { return rv.BarWeight }),
+		"desiredWeight":  resultField(graphql.Int, func(rv *rackResult) any { return rv.DesiredWeight }),
+		"achievedWeight": resultField(graphql.Int, func(rv *rackResult) any { return rv.AchievedWeight }),
+		"exact":          resultField(graphql.Boolean, func(rv *rackResult) any { return rv.AchievedWeight == rv.DesiredWeight }),
+		"strategy":       resultField(graphql.String, func(rv *rackResult) any { return rv.Strategy }),
+		"message":        resultField(graphql.String, func(rv *rackResult) any { return rv.Message }),
+		"loadingOrder": resultField(graphql.NewList(graphql.NewNonNull(graphql.Float)), func(rv *rackResult) any {
+			return rv.LoadingOrder()
+		}),
+		"plates": resultField(graphql.NewList(graphql.NewNonNull(platePairsType)), func(rv *rackResult) any {
+			plates := []platePairs{}
+			for _, plateName := range rack.PlateOrder {
+				if pairs := rv.PlateCount(plateName); pairs > 0 {
+					plates = append(plates, platePairs{Name: plateName, Weight: rack.WeightAmounts[plateName], Pairs: pairs})
+				}
+			}
+			return plates
+		}),
+		"explanation": resultField(explanationType, func(rv *rackResult) any {
+			if rv.Explanation == nil {
+				return nil
+			}
+			return rv.Explanation
+		}),
+	},
+})
+
+var inventoryType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Inventory",
+	Description: "A saved inventory profile. Plate counts are PAIRS.",
+	Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"barWeight":      &graphql.Field{Type: graphql.Int},
+			"hundreds":       &graphql.Field{Type: graphql.Int},
+			"fortyFives":     &graphql.Field{Type: graphql.Int},
+			"thirtyFives":    &graphql.Field{Type: graphql.Int},
+			"twentyFives":    &graphql.Field{Type: graphql.Int},
+			"tens":           &graphql.Field{Type: graphql.Int},
+			"fives":          &graphql.Field{Type: graphql.Int},
+			"twoDotFives":    &graphql.Field{Type: graphql.Int},
+			"oneDotTwoFives": &graphql.Field{Type: graphql.Int},
+			"createdAt":      &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":      &graphql.Field{Type: graphql.DateTime},
+			"rack": &graphql.Field{
+				Type:        rackResultType,
+				Description: "Calculate with this inventory's bar and plates",
+				Args: graphql.FieldConfigArgument{
+					"weight":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"strategy": &graphql.ArgumentConfig{Type: graphql.String},
+					"explain":  &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					inv := p.Source.(*Inventory)
+					query := RackQuery{Inventory: inv.ID}
+					readRackArgs(&query, p.Args)
+					return runRackQuery(p, query)
+				},
+			},
+		}
+	}),
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"rack": &graphql.Field{
+			Type:        rackResultType,
+			Description: "Calculate the plates for a weight. Without plates or an inventory the default plates are used.",
+			Args: graphql.FieldConfigArgument{
+				"weight":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				"bar":       &graphql.ArgumentConfig{Type: graphql.Int, Description: "Bar weight, overrides the plates' or inventory's bar"},
+				"plates":    &graphql.ArgumentConfig{Type: platesInputType},
+				"inventory": &graphql.ArgumentConfig{Type: graphql.ID, Description: "Inventory profile ID, replaces the plates"},
+				"strategy":  &graphql.ArgumentConfig{Type: graphql.String},
+				"explain":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				query := RackQuery{}
+				readRackArgs(&query, p.Args)
+				if inventory, ok := p.Args["inventory"].(string); ok {
+					query.Inventory = inventory
+				}
+				if plates, ok := p.Args["plates"].(map[string]any); ok {
+					input := rack.RackInputStandard{}
+					for _, plateName := range rack.PlateOrder {
+						input.SetPlateCount(plateName, argInt(plates, plateJSONNames[plateName]))
+					}
+					input.BarWeight = argInt(plates, "barWeight")
+					query.Plates = &input
+				}
+				if bar, ok := p.Args["bar"].(int); ok {
+					if query.Plates == nil {
+						defaults := rack.AssumeDefaults()
+						query.Plates = &defaults
+					}
+					query.Plates.BarWeight = bar
+				}
+				return runRackQuery(p, query)
+			},
+		},
+		"bars": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(barType))),
+			Description: "Common bar presets",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return rack.Bars, nil
+			},
+		},
+		"strategies": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+			Description: "Solver strategies accepted by rack",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return rack.Strategies(), nil
+			},
+		},
+		"inventories": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(inventoryType))),
+			Description: "Inventory profiles the API key can use. Needs the read scope.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				principal, err := requireGraphQLScope(p, ScopeRead)
+				if err != nil {
+					return nil, err
+				}
+				inventories := []*Inventory{}
+				for _, inv := range dataStore.ListInventories() {
+					if inv.canAccess(principal) {
+						inventories = append(inventories, inv)
+					}
+				}
+				sort.Slice(inventories, func(i, j int) bool {
+					return inventories[i].Name < inventories[j].Name
+				})
+				return inventories, nil
+			},
+		},
+		"inventory": &graphql.Field{
+			Type:        inventoryType,
+			Description: "One inventory profile. Needs the read scope.",
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if _, err := requireGraphQLScope(p, ScopeRead); err != nil {
+					return nil, err
+				}
+				inv, err := lookupInventory(p.Context, p.Args["id"].(string))
+				if err != nil {
+					return nil, errors.New("inventory not found")
+				}
+				return inv, nil
+			},
+		},
+	},
+})
+
+// plateJSONNames maps plate names to their API field names.
+var plateJSONNames = map[string]string{
+	"Hundos":         "hundreds",
+	"FortyFives":     "fortyFives",
+	"ThirtyFives":    "thirtyFives",
+	"TwentyFives":    "twentyFives",
+	"Tens":           "tens",
+	"Fives":          "fives",
+	"TwoDotFives":    "twoDotFives",
+	"OneDotTwoFives": "oneDotTwoFives",
+}
+
+var graphqlSchema graphql.Schema
+
+func init() {
+	// Added here because the field refers back to its own type
+	rackResultType.AddFieldConfig("alternatives", &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(rackResultType)),
+		Description: "Different loadings from the other strategies, if any",
+		Resolve:     resolveAlternatives,
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic("building GraphQL schema: " + err.Error())
+	}
+	graphqlSchema = schema
+}
+
+// GraphQLHandler serves GraphQL queries over GET (query parameters) and POST (JSON body).
+// Like other GraphQL servers it answers 200 with an errors list when a query fails.
+func GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	request := &GraphQLRequest{}
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				render.Render(w, r, ErrInvalidRequest(errors.New("invalid 'variables' parameter: must be a JSON object")))
+				return
+			}
+		}
+	} else if err := render.DecodeJSON(r.Body, request); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if request.Query == "" {
+		render.Render(w, r, ErrInvalidRequest(errors.New("query is required")))
+		return
+	}
+
+	// Refuse queries that nest or alias their way to unbounded work before running any of it
+	cost := measureGraphQL(request.Query, request.OperationName, len(accessibleInventories(PrincipalFrom(r.Context()))))
+	if err := cost.check(); err != nil {
+		noteRequestError(r.Context(), err.Error())
+		render.JSON(w, r, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	// Limit took one token for the request; each calculation after the first costs another
+	if cost.Calculations > 1 && !rateLimiter.Charge(w, r, cost.Calculations-1) {
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         graphqlSchema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        r.Context(),
+	})
+	render.JSON(w, r, result)
+}
+
+// resultField is a RackResult field computed from the result.
+func resultField(fieldType graphql.Output, value func(rv *rackResult) any) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return value(p.Source.(*rackResult)), nil
+		},
+	}
+}
+
+// readRackArgs copies the weight, strategy and explain arguments onto a query.
+func readRackArgs(query *RackQuery, args map[string]any) {
+	query.Weight, _ = args["weight"].(int)
+	query.Strategy, _ = args["strategy"].(string)
+	query.Explain, _ = args["explain"].(bool)
+}
+
+// runRackQuery calculates a query, keeping it on the result for alternatives.
+func runRackQuery(p graphql.ResolveParams, query RackQuery) (any, error) {
+	result, errResponse := query.Calculate(p.Context)
+	if errResponse != nil {
+		return nil, errors.New(errResponse.ErrorText)
+	}
+	return &rackResult{ReturnedValueStandard: result, query: query}, nil
+}
+
+// resolveAlternatives runs the other strategies on the same query and keeps
+// the ones that load the bar differently.
+func resolveAlternatives(p graphql.ResolveParams) (any, error) {
+	source := p.Source.(*rackResult)
+	seen := [][]float32{source.LoadingOrder()}
+	alternatives := []*rackResult{}
+	for _, strategy := range rack.Strategies() {
+		if strategy == source.Strategy {
+			continue
+		}
+		query := source.query
+		query.Strategy = strategy
+		query.Explain = false
+		result, errResponse := query.Calculate(p.Context)
+		if errResponse != nil {
+			continue // e.g. too heavy for a search strategy
+		}
+		loading := result.LoadingOrder()
+		if slices.ContainsFunc(seen, func(other []float32) bool { return slices.Equal(other, loading) }) {
+			continue
+		}
+		seen = append(seen, loading)
+		alternatives = append(alternatives, &rackResult{ReturnedValueStandard: result, query: query})
+	}
+	return alternatives, nil
+}
+
+// requireGraphQLScope is RequireScope for a GraphQL field.
+func requireGraphQLScope(p graphql.ResolveParams, scope string) (*Principal, error) {
+	principal := PrincipalFrom(p.Context)
+	if principal == nil {
+		return nil, errors.New("an API key is required")
+	}
+	if !principal.HasScope(scope) {
+		return nil, errors.New("API key is missing the '" + scope + "' scope")
+	}
+	return principal, nil
+}
+
+// argInt reads an optional integer from GraphQL input object fields.
+func argInt(fields map[string]any, name string) int {
+	value, _ := fields[name].(int)
+	return value
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeasureGraphQL(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		inventories   int
+		want          graphqlCost
+	}{
+		{"no calculations", `{ bars { name weight } strategies }`, "", 0, graphqlCost{Depth: 2}},
+		{"one calculation", `{ rack(weight: 135) { achievedWeight plates { name pairs } } }`, "", 0, graphqlCost{Depth: 3, Calculations: 1}},
+		{"aliases", `{ a: rack(weight: 135) { exact } b: rack(weight: 145) { exact } c: rack(weight: 155) { exact } }`, "", 0, graphqlCost{Depth: 2, Calculations: 3}},
+		{"alternatives", `{ rack(weight: 135) { alternatives { strategy } } }`, "", 0, graphqlCost{Depth: 3, Calculations: 5}},
+		{"nested alternatives", `{ rack(weight: 135) { alternatives { alternatives { strategy } } } }`, "", 0, graphqlCost{Depth: 4, Calculations: 21}},
+		{"per inventory", `{ inventories { name rack(weight: 225) { exact } } }`, "", 3, graphqlCost{Depth: 3, Calculations: 3}},
+		{"fragments", `
+			query { a: rack(weight: 135) { ...loading } b: rack(weight: 145) { ...loading } }
+			fragment loading on RackResult { exact alternatives { ... on RackResult { strategy } } }`,
+			"", 0, graphqlCost{Depth: 3, Calculations: 10}},
+		{"fragment cycle", `
+			{ rack(weight: 135) { ...a } }
+			fragment a on RackResult { alternatives { ...b } }
+			fragment b on RackResult { alternatives { ...a } }`,
+			"", 0, graphqlCost{Depth: 3, Calculations: 21}},
+		{"named operation", `query small { rack(weight: 135) { exact } } query big { a: rack(weight: 1) { exact } b: rack(weight: 2) { exact } }`, "small", 0, graphqlCost{Depth: 2, Calculations: 1}},
+		{"most expensive operation", `query small { rack(weight: 135) { exact } } query big { a: rack(weight: 1) { exact } b: rack(weight: 2) { exact } }`, "", 0, graphqlCost{Depth: 2, Calculations: 2}},
+		{"syntax error", `{ rack(weight: `, "", 0, graphqlCost{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := measureGraphQL(tt.query, tt.operationName, tt.inventories); got != tt.want {
+				t.Errorf("measureGraphQL() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureGraphQLCapsRunawayQueries(t *testing.T) {
+	query := "{ rack(weight: 135) { " + strings.Repeat("alternatives { ", 40) + "strategy" + strings.Repeat(" }", 40) + " } }"
+	if got := measureGraphQL(query, "", 0); got.Calculations != costCap || got.Depth != 42 {
+		t.Errorf("measureGraphQL() = %+v, want %d calculations at depth 42", got, costCap)
+	}
+}
+
+// graphqlErrors posts a query to GraphQLHandler and returns the error messages.
+func graphqlErrors(t *testing.T, query string) []string {
+	t.Helper()
+	body, _ := json.Marshal(GraphQLRequest{Query: query})
+	rec := serveRequest(http.HandlerFunc(GraphQLHandler), "POST", "/graphql", string(body), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var response struct {
+		Data   map[string]any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	messages := []string{}
+	for _, e := range response.Errors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	useTestStore(t)
+	cache := useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+	t.Setenv("GRAPHQL_MAX_DEPTH", "4")
+	t.Setenv("GRAPHQL_MAX_CALCULATIONS", "10")
+
+	if errs := graphqlErrors(t, `{ rack(weight: 135) { alternatives { strategy } } }`); len(errs) != 0 {
+		t.Fatalf("query within the limits failed: %v", errs)
+	}
+	ran := cache.Stats().Calculations
+
+	tests := []struct {
+		name, query, error string
+	}{
+		{"too deep", `{ rack(weight: 135) { alternatives { alternatives { plates { name } } } } }`, "query is 5 levels deep, more than the limit of 4"},
+		{"too many calculations", `{ rack(weight: 135) { alternatives { alternatives { strategy } } } }`, "query can run up to 21 calculations, more than the limit of 10"},
+		{"too many aliases", `{` + strings.Repeat(` r: rack(weight: 145) { exact }`, 11) + `}`, "query can run up to 11 calculations, more than the limit of 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := graphqlErrors(t, tt.query)
+			if len(errs) != 1 || errs[0] != tt.error {
+				t.Errorf("errors = %q, want %q", errs, tt.error)
+			}
+		})
+	}
+	if got := cache.Stats().Calculations; got != ran {
+		t.Errorf("rejected queries ran %d calculations", got-ran)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/pachev/gorack/rack"
+)
+
+// graphqlCost is what a GraphQL operation asks for before it runs: how
+// deeply its fields nest and how many calculations it can set off. Aliases
+// and fragments count every time they're used, so a query can't hide work
+// behind them.
+type graphqlCost struct {
+	Depth        int
+	Calculations int
+}
+
+// costCap keeps the estimate from overflowing on absurdly nested queries.
+// Anything near it is far over any limit anyway.
+const costCap = 1 << 30
+
+// measureGraphQL works out the cost of the operation a request will run.
+// inventories is how many profiles an inventories field returns for the
+// caller. A query that doesn't parse costs nothing; graphql.Do reports the
+// syntax error.
+func measureGraphQL(query, operationName string, inventories int) graphqlCost {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return graphqlCost{}
+	}
+
+	m := &costMeasurer{fragments: map[string]*ast.FragmentDefinition{}, inventories: inventories}
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			m.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	// Without an operation name only a lone operation runs, but taking the
+	// most expensive one is safe either way
+	var cost graphqlCost
+	for _, operation := range operations {
+		depth, calculations := m.selections(operation.SelectionSet, map[string]bool{})
+		cost.Depth = max(cost.Depth, depth)
+		cost.Calculations = max(cost.Calculations, calculations)
+	}
+	return cost
+}
+
+// check reports an error when the cost goes over GRAPHQL_MAX_DEPTH or
+// GRAPHQL_MAX_CALCULATIONS.
+func (c graphqlCost) check() error {
+	if maxDepth := getEnvInt("GRAPHQL_MAX_DEPTH", 10); c.Depth > maxDepth {
+		return fmt.Errorf("query is %d levels deep, more than the limit of %d", c.Depth, maxDepth)
+	}
+	if maxCalculations := getEnvInt("GRAPHQL_MAX_CALCULATIONS", 100); c.Calculations > maxCalculations {
+		return fmt.Errorf("query can run up to %d calculations, more than the limit of %d", c.Calculations, maxCalculations)
+	}
+	return nil
+}
+
+// costMeasurer walks an operation's selections.
+type costMeasurer struct {
+	fragments   map[string]*ast.FragmentDefinition
+	inventories int
+}
+
+// selections returns the depth of a selection set and the calculations its
+// fields can run. Fragments being expanded are in visiting, so a cycle (which
+// validation rejects later) can't recurse forever.
+func (m *costMeasurer) selections(set *ast.SelectionSet, visiting map[string]bool) (depth, calculations int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, c = m.field(selection, visiting)
+		case *ast.InlineFragment:
+			d, c = m.selections(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := m.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			d, c = m.selections(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		depth = max(depth, d)
+		calculations = min(calculations+c, costCap)
+	}
+	return depth, calculations
+}
+
+// field returns a field's depth, counting itself, and its calculations.
+// rack runs one calculation; alternatives runs every other strategy, and
+// its subfields run once per alternative, as they do for each inventory.
+func (m *costMeasurer) field(field *ast.Field, visiting map[string]bool) (depth, calculations int) {
+	depth, calculations = m.selections(field.SelectionSet, visiting)
+	switch field.Name.Value {
+	case "rack":
+		calculations = min(calculations+1, costCap)
+	case "alternatives":
+		others := len(rack.Strategies()) - 1
+		calculations = min(others*(calculations+1), costCap)
+	case "inventories":
+		calculations = min(m.inventories*calculations, costCap)
+	}
+	return depth + 1, calculations
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pachev/gorack/rack"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	gorackv1 "github.com/pachev/gorack/proto/gorack/v1"
+)
+
+// rackServer implements the gRPC RackService. It goes through the same
+// solvers and WeightCache as the REST handlers.
+type rackServer struct {
+	gorackv1.UnimplementedRackServiceServer
+	limiter *RateLimiter // Charges batches and charts per calculation
+}
+
+// serveGRPC runs the gRPC server on port until the process exits.
+func serveGRPC(port string) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		fatal("Listening for gRPC failed", "port", port, "err", err)
+	}
+	server := newGRPCServer(rateLimiter)
+	slog.Info("Starting gRPC server", "port", port)
+	fatal("gRPC server stopped", "err", server.Serve(listener))
+}
+
+// newGRPCServer sets up the RackService with tracing, metrics, logging, auth
+// and limiter's rate limits on every call.
+func newGRPCServer(limiter *RateLimiter) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()), // Server spans, continuing the caller's trace
+		grpc.ChainUnaryInterceptor(metricsRPC, logRPC, authenticateRPC, limiter.LimitRPC),
+	)
+	gorackv1.RegisterRackServiceServer(server, &rackServer{limiter: limiter})
+	reflection.Register(server)
+	return server
+}
+
+// authenticateRPC is the gRPC version of Authenticate, plus RequireScope(ScopeRead)
+// when AUTH_REQUIRED is set. The key goes in the "authorization" metadata.
+func authenticateRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "authorization header must use the Bearer scheme")
+		}
+		principal, err := authenticateToken(strings.TrimSpace(token))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = context.WithValue(ctx, principalContextKey{}, principal)
+	}
+
+	if getEnvBool("AUTH_REQUIRED", false) {
+		principal := PrincipalFrom(ctx)
+		if principal == nil {
+			return nil, status.Error(codes.Unauthenticated, "an API key is required")
+		}
+		if !principal.HasScope(ScopeRead) {
+			return nil, status.Error(codes.PermissionDenied, "API key is missing the '"+ScopeRead+"' scope")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// Calculate mirrors RackEmGet when no plates are given and RackEmPost otherwise.
+func (s *rackServer) Calculate(ctx context.Context, req *gorackv1.CalculateRequest) (*gorackv1.CalculateResponse, error) {
+	query := &RackQuery{
+		Weight:    int(req.DesiredWeight),
+		Inventory: req.Inventory,
+		Strategy:  req.Strategy,
+		Explain:   req.Explain,
+	}
+	if req.Plates != nil {
+		plates := platesFromProto(req.Plates)
+		query.Plates = &plates
+	}
+	result, errResponse := query.Calculate(ctx)
+	if errResponse != nil {
+		return nil, errorStatus(errResponse)
+	}
+	return resultToProto(result), nil
+}
+
+// BatchCalculate mirrors RackEmBatch: each item fails on its own.
+func (s *rackServer) BatchCalculate(ctx context.Context, req *gorackv1.BatchCalculateRequest) (*gorackv1.BatchCalculateResponse, error) {
+	if err := validateBatchSize(len(req.Requests)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// LimitRPC took one token for the call; each item after the first costs another
+	if len(req.Requests) > 1 {
+		if err := s.limiter.ChargeRPC(ctx, len(req.Requests)-1); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &gorackv1.BatchCalculateResponse{Results: make([]*gorackv1.BatchResult, len(req.Requests))}
+	for i, item := range req.Requests {
+		result := &gorackv1.BatchResult{Index: int32(i)}
+		if calculated, err := s.Calculate(ctx, item); err != nil {
+			st := status.Convert(err)
+			result.Error = &gorackv1.BatchError{Code: st.Code().String(), Message: st.Message()}
+		} else {
+			result.Result = calculated
+		}
+		response.Results[i] = result
+	}
+	return response, nil
+}
+
+// Chart mirrors RackEmChart's JSON output.
+func (s *rackServer) Chart(ctx context.Context, req *gorackv1.ChartRequest) (*gorackv1.ChartResponse, error) {
+	chart := &ChartRequest{Plates: rack.AssumeDefaults(), Inventory: req.Inventory}
+	if req.Plates != nil {
+		chart.Plates = platesFromProto(req.Plates)
+		if chart.Plates.BarWeight == 0 {
+			chart.Plates.BarWeight = rack.AssumeDefaults().BarWeight
+		}
+	}
+	if req.Inventory != "" {
+		inv, err := lookupInventory(ctx, req.Inventory)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "inventory not found")
+		}
+		if req.Plates == nil {
+			chart.Plates.BarWeight = 0
+		}
+		inv.Apply(&chart.Plates)
+	}
+
+	var err error
+	if chart.Plates.Strategy, err = parseStrategy(req.Strategy); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	chart.From, chart.To, chart.Step = int(req.From), int(req.To), int(req.Step)
+	if chart.From == 0 {
+		chart.From = chart.Plates.BarWeight
+	}
+	if chart.Step == 0 {
+		chart.Step = 5
+	}
+	if err := chart.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if rows := chart.rows(); rows > 1 {
+		if err := s.limiter.ChargeRPC(ctx, rows-1); err != nil {
+			return nil, err
+		}
+	}
+
+	built, err := BuildChart(ctx, chart)
+	if err != nil {
+		slog.ErrorContext(ctx, "Building chart failed", "err", err, "request", chart)
+		return nil, errorStatus(ErrCalculation(err).(*ErrResponse))
+	}
+	return chartToProto(built), nil
+}
+
+// errorStatus converts an error response to the matching gRPC status.
+func errorStatus(e *ErrResponse) error {
+	code := codes.Internal
+	switch e.HTTPStatusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	}
+	return status.Error(code, e.ErrorText)
+}
+
+// platesFromProto converts gRPC plates to the calculator's input.
+func platesFromProto(plates *gorackv1.Plates) rack.RackInputStandard {
+	return rack.RackInputStandard{
+		BarWeight:      int(plates.BarWeight),
+		Hundos:         int(plates.Hundreds),
+		FortyFives:     int(plates.FortyFives),
+		ThirtyFives:    int(plates.ThirtyFives),
+		TwentyFives:    int(plates.TwentyFives),
+		Tens:           int(plates.Tens),
+		Fives:          int(plates.Fives),
+		TwoDotFives:    int(plates.TwoDotFives),
+		OneDotTwoFives: int(plates.OneDotTwoFives),
+	}
+}
+
+// platesToProto converts the calculator's plates to gRPC plates.
+func platesToProto(plates *rack.RackInputStandard) *gorackv1.Plates {
+	return &gorackv1.Plates{
+		BarWeight:      int32(plates.BarWeight),
+		Hundreds:       int32(plates.Hundos),
+		FortyFives:     int32(plates.FortyFives),
+		ThirtyFives:    int32(plates.ThirtyFives),
+		TwentyFives:    int32(plates.TwentyFives),
+		Tens:           int32(plates.Tens),
+		Fives:          int32(plates.Fives),
+		TwoDotFives:    int32(plates.TwoDotFives),
+		OneDotTwoFives: int32(plates.OneDotTwoFives),
+	}
+}
+
+// resultToProto converts a calculation result to its gRPC response.
+func resultToProto(result *rack.ReturnedValueStandard) *gorackv1.CalculateResponse {
+	response := &gorackv1.CalculateResponse{
+		Plates:         platesToProto(result.RackInputStandard),
+		DesiredWeight:  int32(result.DesiredWeight),
+		AchievedWeight: int32(result.AchievedWeight),
+		Strategy:       result.Strategy,
+		Message:        result.Message,
+	}
+	if result.Explanation != nil {
+		response.Explanation = &gorackv1.Explanation{Note: result.Explanation.Note}
+		for _, step := range result.Explanation.Steps {
+			response.Explanation.Steps = append(response.Explanation.Steps, &gorackv1.Step{
+				Plate:     step.Plate,
+				Pairs:     int32(step.Pairs),
+				Skipped:   step.Skipped,
+				Remaining: int32(step.Remaining),
+			})
+		}
+	}
+	return response
+}
+
+// chartToProto converts a loading chart to its gRPC response.
+func chartToProto(chart *ChartResponse) *gorackv1.ChartResponse {
+	response := &gorackv1.ChartResponse{
+		BarWeight: int32(chart.BarWeight),
+		From:      int32(chart.From),
+		To:        int32(chart.To),
+		Step:      int32(chart.Step),
+		Inventory: chart.Inventory,
+		Strategy:  chart.Strategy,
+		Rows:      make([]*gorackv1.ChartRow, len(chart.Rows)),
+	}
+	for i, row := range chart.Rows {
+		response.Rows[i] = &gorackv1.ChartRow{
+			Weight:         int32(row.Weight),
+			AchievedWeight: int32(row.AchievedWeight),
+			Loadable:       row.Loadable,
+			PerSide:        row.PerSide,
+		}
+	}
+	return response
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	gorackv1 "github.com/pachev/gorack/proto/gorack/v1"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+// recordSpans installs a tracer provider that keeps every span in memory.
+// The global provider can only be replaced once per process, so every test
+// shares the recorder.
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	})
+	return spans
+}
+
+// captureLogs sends slog's output to a buffer as JSON for the length of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(contextHandler{slog.NewJSONHandler(&buf, nil)}))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// dialTestGRPC starts newGRPCServer on an in-memory listener, limiting
+// calls by limits, and returns a client.
+func dialTestGRPC(t *testing.T, limits RateLimitConfig) gorackv1.RackServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer(NewRateLimiter(limits))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return gorackv1.NewRackServiceClient(conn)
+}
+
+// counterValue reads a counter's current value.
+func counterValue(t *testing.T, counter interface{ Write(*dto.Metric) error }) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	recorder := recordSpans()
+	logs := captureLogs(t)
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	client := dialTestGRPC(t, RateLimitConfig{})
+
+	method := gorackv1.RackService_Calculate_FullMethodName
+	ok := grpcRequests.WithLabelValues(method, codes.OK.String())
+	unauthenticated := grpcRequests.WithLabelValues(method, codes.Unauthenticated.String())
+	okBefore, unauthenticatedBefore := counterValue(t, ok), counterValue(t, unauthenticated)
+
+	result, err := client.Calculate(context.Background(), &gorackv1.CalculateRequest{DesiredWeight: 135})
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if result.AchievedWeight != 135 {
+		t.Errorf("achieved = %d, want 135", result.AchievedWeight)
+	}
+	badKey := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer grk_nope_nope")
+	if _, err := client.Calculate(badKey, &gorackv1.CalculateRequest{DesiredWeight: 135}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Calculate with a bad key: %v, want Unauthenticated", err)
+	}
+
+	if got := counterValue(t, ok) - okBefore; got != 1 {
+		t.Errorf("OK calls counted = %v, want 1", got)
+	}
+	if got := counterValue(t, unauthenticated) - unauthenticatedBefore; got != 1 {
+		t.Errorf("Unauthenticated calls counted = %v, want 1", got)
+	}
+
+	// Both calls get a server span, and the solver's spans nest under the first
+	var serverSpans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == strings.TrimPrefix(method, "/") {
+			serverSpans = append(serverSpans, span)
+		}
+	}
+	if len(serverSpans) < 2 {
+		t.Fatalf("got %d server spans for %s, want 2", len(serverSpans), method)
+	}
+	traceID := serverSpans[len(serverSpans)-2].SpanContext().TraceID()
+	nested := false
+	for _, span := range recorder.Ended() {
+		if span.Name() == "table.lookup" && span.SpanContext().TraceID() == traceID {
+			nested = true
+		}
+	}
+	if !nested {
+		t.Error("table.lookup span isn't part of the gRPC call's trace")
+	}
+
+	// One request line per call, carrying the trace ID
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if record["msg"] == "request" {
+			lines = append(lines, record)
+		}
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d request log lines, want 2: %s", len(lines), logs)
+	}
+	if lines[0]["route"] != method || lines[0]["grpc_code"] != "OK" || lines[0]["trace_id"] != traceID.String() {
+		t.Errorf("first log line = %v", lines[0])
+	}
+	if lines[1]["grpc_code"] != "Unauthenticated" || lines[1]["error"] != "invalid API key" {
+		t.Errorf("second log line = %v", lines[1])
+	}
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	limits, err := ParseRateLimits("GRPC /gorack.v1.RackService/Calculate=2/m, *=100/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dialTestGRPC(t, RateLimitConfig{Limits: limits, DailyQuota: 3})
+	_, token := newTestUser(t, "sam", AllScopes...)
+	keyed := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	var header metadata.MD
+	for i := 0; i < 2; i++ {
+		if _, err := client.Calculate(context.Background(), &gorackv1.CalculateRequest{DesiredWeight: 135}, grpc.Header(&header)); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	if got := header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Errorf("ratelimit-remaining = %v, want 0", got)
+	}
+	limited := rateLimited.WithLabelValues(gorackv1.RackService_Calculate_FullMethodName, "rate")
+	before := counterValue(t, limited)
+	_, err = client.Calculate(context.Background(), &gorackv1.CalculateRequest{DesiredWeight: 135}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("third call: %v, want ResourceExhausted", err)
+	}
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "30" {
+		t.Errorf("retry-after = %v, want 30", got)
+	}
+	if got := counterValue(t, limited) - before; got != 1 {
+		t.Errorf("rate limited calls counted = %v, want 1", got)
+	}
+
+	// A key has its own bucket, and its quota covers every method
+	for i := 0; i < 2; i++ {
+		if _, err := client.Calculate(keyed, &gorackv1.CalculateRequest{DesiredWeight: 135}); err != nil {
+			t.Fatalf("keyed call %d: %v", i+1, err)
+		}
+	}
+	if _, err := client.Chart(keyed, &gorackv1.ChartRequest{From: 135, To: 145, Step: 5}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Chart: %v", err)
+	}
+	if got := header.Get("ratelimit-policy"); len(got) != 1 || got[0] != "100;w=60, 3;w=86400" {
+		t.Errorf("ratelimit-policy = %v", got)
+	}
+	if _, err := client.Chart(keyed, &gorackv1.ChartRequest{From: 135, To: 145, Step: 5}); status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "daily quota") {
+		t.Errorf("Chart over the quota: %v, want ResourceExhausted", err)
+	}
+}
+
+func TestGRPCChargesPerCalculation(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	limits, err := ParseRateLimits("GRPC /gorack.v1.RackService/Chart=10/m, GRPC /gorack.v1.RackService/BatchCalculate=5/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dialTestGRPC(t, RateLimitConfig{Limits: limits})
+
+	var header metadata.MD
+	if _, err := client.Chart(context.Background(), &gorackv1.ChartRequest{From: 135, To: 145, Step: 5}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Chart: %v", err)
+	}
+	if got := header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "7" {
+		t.Errorf("ratelimit-remaining after 3 rows = %v, want 7", got)
+	}
+	_, err = client.Chart(context.Background(), &gorackv1.ChartRequest{From: 135, To: 180, Step: 5}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("10 rows with 7 left: %v, want ResourceExhausted", err)
+	}
+	if got := header.Get("retry-after"); len(got) != 1 {
+		t.Errorf("retry-after = %v, want one value", got)
+	}
+
+	batch := func(items int) *gorackv1.BatchCalculateRequest {
+		req := &gorackv1.BatchCalculateRequest{}
+		for range items {
+			req.Requests = append(req.Requests, &gorackv1.CalculateRequest{DesiredWeight: 135})
+		}
+		return req
+	}
+	if _, err := client.BatchCalculate(context.Background(), batch(7)); status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "costs more than") {
+		t.Errorf("7 items with a limit of 5: %v, want ResourceExhausted", err)
+	}
+	if _, err := client.BatchCalculate(context.Background(), batch(3), grpc.Header(&header)); err != nil {
+		t.Fatalf("BatchCalculate: %v", err)
+	}
+	if got := header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "1" {
+		t.Errorf("ratelimit-remaining after 3 items = %v, want 1", got)
+	}
+}
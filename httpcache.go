@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// rackETag is a strong validator for a /rack response: a digest of the
+// result and the format it's sent in, so it changes whenever the body does,
+// including after a deploy that changes what the solvers return.
+func rackETag(r *http.Request, result *rack.ReturnedValueStandard) string {
+	hash := sha256.New()
+	io.WriteString(hash, negotiatedFormat(r)+"\x00")
+	json.NewEncoder(hash).Encode(result) // A hash never fails to write
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// rackCacheControl is the Cache-Control value for GET /rack. Results for the
+// default plates are the same for everyone, so shared caches may keep them
+// for CACHE_TTL, unless the request was authenticated: those stay out of
+// shared caches. Inventory plates belong to one account and can change at
+// any time, so those results are revalidated with the ETag on every use.
+func rackCacheControl(r *http.Request, inventory bool) string {
+	if inventory {
+		return "private, no-cache"
+	}
+	scope := "public"
+	if PrincipalFrom(r.Context()) != nil {
+		scope = "private"
+	}
+	ttl := weightCache.TTL()
+	if ttl <= 0 {
+		return scope + ", no-cache"
+	}
+	return scope + ", max-age=" + strconv.Itoa(int(ttl.Seconds()))
+}
+
+// notModified sets the caching headers for a response and, when the
+// request's If-None-Match already has etag, answers 304 Not Modified. It
+// reports whether the response was written.
+func notModified(w http.ResponseWriter, r *http.Request, etag, cacheControl string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. As the
+// header requires, weak tags match their strong counterparts.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// rackRouter serves GET /rack behind Authenticate, as the API does.
+func rackRouter() http.Handler {
+	router := chi.NewRouter()
+	router.With(Authenticate).Get("/v1/api/rack", RackEmGet)
+	return router
+}
+
+func TestRackETag(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute, MaxEntries: 100})
+	putSharedInventory(t)
+	router := rackRouter()
+
+	first := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET /rack: %d, ETag %q", first.Code, etag)
+	}
+	if again := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", ""); again.Header().Get("ETag") != etag {
+		t.Errorf("ETag changed between requests: %q, then %q", etag, again.Header().Get("ETag"))
+	}
+
+	// Anything that changes the response changes the tag
+	for _, target := range []string{
+		"/v1/api/rack?weight=325",
+		"/v1/api/rack?weight=315&strategy=keep-big-free",
+		"/v1/api/rack?weight=315&explain=true",
+		"/v1/api/rack?weight=315&inventory=shared",
+	} {
+		if got := serveRequest(router, "GET", target, "", "").Header().Get("ETag"); got == etag || got == "" {
+			t.Errorf("GET %s: ETag %q, want one other than %q", target, got, etag)
+		}
+	}
+	if got := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "", "Accept", "application/yaml").Header().Get("ETag"); got == etag {
+		t.Errorf("YAML response has the JSON ETag %q", got)
+	}
+
+	// The tag follows the result, not the plates asked about
+	inventoryTag := func(update func(inv *Inventory)) string {
+		t.Helper()
+		inv, err := dataStore.GetInventory("shared")
+		if err != nil {
+			t.Fatal(err)
+		}
+		update(inv)
+		if err := dataStore.PutInventory(inv); err != nil {
+			t.Fatal(err)
+		}
+		return serveRequest(router, "GET", "/v1/api/rack?weight=315&inventory=shared", "", "").Header().Get("ETag")
+	}
+	before := inventoryTag(func(inv *Inventory) {})
+	if got := inventoryTag(func(inv *Inventory) { inv.Tens = 2 }); got != before {
+		t.Errorf("unused tens changed the ETag from %q to %q", before, got)
+	}
+	if got := inventoryTag(func(inv *Inventory) { inv.Hundos = 1 }); got == before {
+		t.Errorf("loading a hundred kept the ETag %q", got)
+	}
+}
+
+func TestRackNotModified(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute, MaxEntries: 100})
+	router := rackRouter()
+	etag := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "").Header().Get("ETag")
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"same tag", etag, http.StatusNotModified},
+		{"weak tag", "W/" + etag, http.StatusNotModified},
+		{"in a list", `"other", ` + etag, http.StatusNotModified},
+		{"any", "*", http.StatusNotModified},
+		{"other tag", `"other"`, http.StatusOK},
+		{"unquoted", etag[1 : len(etag)-1], http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "", "If-None-Match", tt.ifNoneMatch)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Header().Get("ETag") != etag || rec.Header().Get("Cache-Control") == "" {
+				t.Errorf("headers = %v, want the ETag and Cache-Control", rec.Header())
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 has a body: %q", rec.Body.String())
+			}
+		})
+	}
+
+	// The tag for one format doesn't validate another
+	rec := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "", "If-None-Match", etag, "Accept", "application/yaml")
+	if rec.Code != http.StatusOK {
+		t.Errorf("YAML with the JSON ETag: status %d, want 200", rec.Code)
+	}
+}
+
+func TestRackCacheControl(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute, MaxEntries: 100})
+	putSharedInventory(t)
+	router := rackRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	tests := []struct {
+		name   string
+		target string
+		token  string
+		want   string
+	}{
+		{"anonymous", "/v1/api/rack?weight=315", "", "public, max-age=60"},
+		{"authenticated", "/v1/api/rack?weight=315", token, "private, max-age=60"},
+		{"inventory", "/v1/api/rack?weight=315&inventory=shared", "", "private, no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(router, "GET", tt.target, "", tt.token)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	useTestCache(t, CacheConfig{MaxEntries: 100})
+	if got := serveRequest(router, "GET", "/v1/api/rack?weight=315", "", "").Header().Get("Cache-Control"); got != "public, no-cache" {
+		t.Errorf("Cache-Control without a TTL = %q, want public, no-cache", got)
+	}
+}
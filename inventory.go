@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/pachev/gorack/rack"
+)
+
+// Inventory is a named, persisted set of available plates and a bar weight
+// (for example "garage" or "main floor"). Plate counts are number of PAIRS.
+type Inventory struct {
+	ID             string    `json:"id"`
+	OwnerID        string    `json:"ownerId,omitempty"`
+	Name           string    `json:"name"`
+	BarWeight      int       `json:"barWeight,omitempty"`
+	Hundos         int       `json:"hundreds,omitempty"`
+	FortyFives     int       `json:"fortyFives,omitempty"`
+	ThirtyFives    int       `json:"thirtyFives,omitempty"`
+	TwentyFives    int       `json:"twentyFives,omitempty"`
+	Tens           int       `json:"tens,omitempty"`
+	Fives          int       `json:"fives,omitempty"`
+	TwoDotFives    int       `json:"twoDotFives,omitempty"`
+	OneDotTwoFives int       `json:"oneDotTwoFives,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Apply copies the inventory's plates onto a rack input, replacing any listed plates.
+// The inventory's bar weight is only used when the input does not set one.
+func (inv *Inventory) Apply(input *rack.RackInputStandard) {
+	if input.BarWeight == 0 {
+		input.BarWeight = inv.BarWeight
+	}
+	input.Hundos = inv.Hundos
+	input.FortyFives = inv.FortyFives
+	input.ThirtyFives = inv.ThirtyFives
+	input.TwentyFives = inv.TwentyFives
+	input.Tens = inv.Tens
+	input.Fives = inv.Fives
+	input.TwoDotFives = inv.TwoDotFives
+	input.OneDotTwoFives = inv.OneDotTwoFives
+}
+
+// canAccess reports whether a caller may use an inventory profile. Profiles
+// created before accounts existed have no owner and stay shared.
+func (inv *Inventory) canAccess(principal *Principal) bool {
+	if inv.OwnerID == "" {
+		return true
+	}
+	return principal != nil && principal.User.ID == inv.OwnerID
+}
+
+// canModify reports whether a caller may update or delete an inventory
+// profile. Shared profiles are read-only unless the request carries the
+// admin key.
+func (inv *Inventory) canModify(principal *Principal, admin bool) bool {
+	if inv.OwnerID == "" {
+		return admin
+	}
+	return principal != nil && principal.User.ID == inv.OwnerID
+}
+
+// errSharedInventory is reported when a caller tries to change a shared profile.
+var errSharedInventory = errors.New("shared inventory profiles can only be changed with the admin key")
+
+// lookupInventory returns an inventory profile the caller is allowed to use.
+// Profiles owned by someone else are reported as not found.
+func lookupInventory(ctx context.Context, id string) (*Inventory, error) {
+	inv, err := dataStore.GetInventory(id)
+	if err != nil {
+		return nil, err
+	}
+	if !inv.canAccess(PrincipalFrom(ctx)) {
+		return nil, ErrRecordNotFound
+	}
+	return inv, nil
+}
+
+// InventoryRequest is the payload for creating or updating an inventory profile.
+type InventoryRequest struct {
+	Name           string `json:"name"`
+	BarWeight      int    `json:"barWeight,omitempty"`
+	Hundos         int    `json:"hundreds,omitempty"`
+	FortyFives     int    `json:"fortyFives,omitempty"`
+	ThirtyFives    int    `json:"thirtyFives,omitempty"`
+	TwentyFives    int    `json:"twentyFives,omitempty"`
+	Tens           int    `json:"tens,omitempty"`
+	Fives          int    `json:"fives,omitempty"`
+	TwoDotFives    int    `json:"twoDotFives,omitempty"`
+	OneDotTwoFives int    `json:"oneDotTwoFives,omitempty"`
+}
+
+// Bind validates an inventory payload.
+func (ir *InventoryRequest) Bind(r *http.Request) error {
+	ir.Name = strings.TrimSpace(ir.Name)
+	if ir.Name == "" {
+		return errors.New("inventory name is required")
+	}
+	if ir.BarWeight == 0 {
+		ir.BarWeight = rack.AssumeDefaults().BarWeight
+	}
+	if ir.BarWeight < 0 {
+		return errors.New("bar weight cannot be negative")
+	}
+	for _, count := range []int{
+		ir.Hundos, ir.FortyFives, ir.ThirtyFives, ir.TwentyFives,
+		ir.Tens, ir.Fives, ir.TwoDotFives, ir.OneDotTwoFives,
+	} {
+		if count < 0 {
+			return errors.New("plate counts cannot be negative")
+		}
+	}
+	return nil
+}
+
+// applyTo copies the request fields onto a stored inventory.
+func (ir *InventoryRequest) applyTo(inv *Inventory) {
+	inv.Name = ir.Name
+	inv.BarWeight = ir.BarWeight
+	inv.Hundos = ir.Hundos
+	inv.FortyFives = ir.FortyFives
+	inv.ThirtyFives = ir.ThirtyFives
+	inv.TwentyFives = ir.TwentyFives
+	inv.Tens = ir.Tens
+	inv.Fives = ir.Fives
+	inv.TwoDotFives = ir.TwoDotFives
+	inv.OneDotTwoFives = ir.OneDotTwoFives
+}
+
+// InventoryRoutes mounts the inventory profile CRUD endpoints.
+func InventoryRoutes(r chi.Router) {
+	r.With(RequireScope(ScopeRead)).Get("/", ListInventories)
+	r.With(RequireScope(ScopeWrite)).Post("/", CreateInventory)
+	r.With(RequireScope(ScopeRead)).Get("/{inventoryID}", GetInventory)
+	r.With(RequireScope(ScopeWrite)).Put("/{inventoryID}", UpdateInventory)
+	r.With(RequireScope(ScopeWrite)).Delete("/{inventoryID}", DeleteInventory)
+}
+
+// ListInventories godoc
+// @Summary      List inventory profiles
+// @Description  Returns the caller's saved inventory profiles ordered by name
+// @Tags         Inventory
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   Inventory
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Router       /inventories [get]
+func ListInventories(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, accessibleInventories(PrincipalFrom(r.Context())))
+}
+
+// accessibleInventories returns the profiles principal may use, ordered by name.
+func accessibleInventories(principal *Principal) []*Inventory {
+	inventories := []*Inventory{}
+	for _, inv := range dataStore.ListInventories() {
+		if inv.canAccess(principal) {
+			inventories = append(inventories, inv)
+		}
+	}
+	sort.Slice(inventories, func(i, j int) bool {
+		return inventories[i].Name < inventories[j].Name
+	})
+	return inventories
+}
+
+// CreateInventory godoc
+// @Summary      Create an inventory profile
+// @Description  Saves a named set of available plates and a bar weight
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      InventoryRequest  true  "Inventory name, bar weight and available plates"
+// @Success      201      {object}  Inventory
+// @Failure      400      {object}  ErrResponse
+// @Failure      401      {object}  ErrResponse
+// @Failure      403      {object}  ErrResponse
+// @Failure      500      {object}  ErrResponse
+// @Router       /inventories [post]
+func CreateInventory(w http.ResponseWriter, r *http.Request) {
+	input := &InventoryRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Generating inventory ID failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	now := time.Now().UTC()
+	inv := &Inventory{
+		ID:        id,
+		OwnerID:   PrincipalFrom(r.Context()).User.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	input.applyTo(inv)
+
+	if err := dataStore.PutInventory(inv); err != nil {
+		slog.ErrorContext(r.Context(), "Saving inventory failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, inv)
+}
+
+// GetInventory godoc
+// @Summary      Get an inventory profile
+// @Tags         Inventory
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        inventoryID  path      string  true  "Inventory ID"
+// @Success      200          {object}  Inventory
+// @Failure      401          {object}  ErrResponse
+// @Failure      403          {object}  ErrResponse
+// @Failure      404          {object}  ErrResponse
+// @Router       /inventories/{inventoryID} [get]
+func GetInventory(w http.ResponseWriter, r *http.Request) {
+	inv, err := lookupInventory(r.Context(), chi.URLParam(r, "inventoryID"))
+	if err != nil {
+		render.Render(w, r, ErrNotFound(errors.New("inventory not found")))
+		return
+	}
+	render.JSON(w, r, inv)
+}
+
+// UpdateInventory godoc
+// @Summary      Update an inventory profile
+// @Description  Replaces the name, bar weight and plates of an inventory profile
+// @Tags         Inventory
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        inventoryID  path      string            true  "Inventory ID"
+// @Param        request      body      InventoryRequest  true  "Inventory name, bar weight and available plates"
+// @Success      200          {object}  Inventory
+// @Failure      400          {object}  ErrResponse
+// @Failure      401          {object}  ErrResponse
+// @Failure      403          {object}  ErrResponse
+// @Failure      404          {object}  ErrResponse
+// @Failure      500          {object}  ErrResponse
+// @Router       /inventories/{inventoryID} [put]
+func UpdateInventory(w http.ResponseWriter, r *http.Request) {
+	inv, err := lookupInventory(r.Context(), chi.URLParam(r, "inventoryID"))
+	if err != nil {
+		render.Render(w, r, ErrNotFound(errors.New("inventory not found")))
+		return
+	}
+	if !inv.canModify(PrincipalFrom(r.Context()), isAdmin(r)) {
+		render.Render(w, r, ErrForbidden(errSharedInventory))
+		return
+	}
+
+	input := &InventoryRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	input.applyTo(inv)
+	inv.UpdatedAt = time.Now().UTC()
+
+	if err := dataStore.PutInventory(inv); err != nil {
+		slog.ErrorContext(r.Context(), "Saving inventory failed", "inventory", inv.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	render.JSON(w, r, inv)
+}
+
+// DeleteInventory godoc
+// @Summary      Delete an inventory profile
+// @Tags         Inventory
+// @Security     ApiKeyAuth
+// @Param        inventoryID  path  string  true  "Inventory ID"
+// @Success      204
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Failure      404  {object}  ErrResponse
+// @Failure      500  {object}  ErrResponse
+// @Router       /inventories/{inventoryID} [delete]
+func DeleteInventory(w http.ResponseWriter, r *http.Request) {
+	inv, err := lookupInventory(r.Context(), chi.URLParam(r, "inventoryID"))
+	if err != nil {
+		render.Render(w, r, ErrNotFound(errors.New("inventory not found")))
+		return
+	}
+	if !inv.canModify(PrincipalFrom(r.Context()), isAdmin(r)) {
+		render.Render(w, r, ErrForbidden(errSharedInventory))
+		return
+	}
+	if err := dataStore.DeleteInventory(inv.ID); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			render.Render(w, r, ErrNotFound(errors.New("inventory not found")))
+			return
+		}
+		slog.ErrorContext(r.Context(), "Deleting inventory failed", "inventory", inv.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	render.NoContent(w, r)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// setupLogging makes a structured logger the default for slog and the log
+// package. LOG_FORMAT picks "json" (the default) or "text", and LOG_LEVEL
+// one of "debug", "info" (the default), "warn" or "error".
+func setupLogging(w io.Writer) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(getEnv("LOG_LEVEL", "info"))); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format := strings.ToLower(getEnv("LOG_FORMAT", "json")); format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("unknown LOG_FORMAT %q: must be json or text", format)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// fatal logs msg at error level and exits, like log.Fatal.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler adds the request ID and trace ID from the context to every
+// record logged with one of slog's *Context functions.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// requestErrorKey holds the *string ErrResponse.Render fills in, so the
+// request's log line says why it failed.
+type requestErrorKey struct{}
+
+// noteRequestError records the error a response reported for RequestLogger.
+func noteRequestError(ctx context.Context, text string) {
+	if holder, ok := ctx.Value(requestErrorKey{}).(*string); ok {
+		*holder = text
+	}
+}
+
+// RequestLogger logs one line per request with its route, status, size,
+// latency and, when set, the X-Cache result and the error sent back. It
+// replaces middleware.Logger and runs after middleware.RequestID and
+// Tracing so the line carries both IDs.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		var errorText string
+		ctx := context.WithValue(r.Context(), requestErrorKey{}, &errorText)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := responseStatus(ww)
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", routePattern(r)),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote", r.RemoteAddr),
+		}
+		if cache := ww.Header().Get("X-Cache"); cache != "" {
+			attrs = append(attrs, slog.Bool("cache_hit", cache == "HIT"))
+		}
+		if errorText != "" {
+			attrs = append(attrs, slog.String("error", errorText))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request", attrs...)
+	})
+}
+
+// logRPC is RequestLogger for the gRPC service. The route is the full
+// method name and the status its gRPC code, and the trace ID comes from the
+// span otelgrpc starts.
+func logRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	st := status.Convert(err)
+	attrs := []slog.Attr{
+		slog.String("method", "gRPC"),
+		slog.String("route", info.FullMethod),
+		slog.String("grpc_code", st.Code().String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String("remote", p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+
+	level := slog.LevelInfo
+	switch st.Code() {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		level = slog.LevelError
+	}
+	slog.LogAttrs(ctx, level, "request", attrs...)
+	return resp, err
+}
@@ -16,203 +16,259 @@
 // @tag.name Rack
 // @tag.description Operations for calculating barbell weight plates
 
+// @tag.name Inventory
+// @tag.description Saved plate inventory profiles
+
+// @tag.name Auth
+// @tag.description User accounts and API keys
+
+// @tag.name Workouts
+// @tag.description Workout logging and estimated one-rep max trends
+
+// @tag.name Admin
+// @tag.description Operator endpoints for the result cache
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        Authorization
+// @description                 API key as "Bearer grk_..."
+
+// @securityDefinitions.apikey  AdminKey
+// @in                          header
+// @name                        X-Admin-Key
+// @description                 The server's ADMIN_API_KEY
+
 package main
 
 import (
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"reflect"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
-	"github.com/mitchellh/mapstructure"
-
-   httpSwagger "github.com/swaggo/http-swagger/v2"
-   _ "github.com/pachev/gorack/docs"
+	"github.com/pachev/gorack/rack"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	_ "github.com/pachev/gorack/docs"
+	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
-// WeightCache provides in-memory caching for weight calculations
-type WeightCache struct {
-	cache map[string]*ReturnedValueStandard
-	mu    sync.RWMutex
-	ttl   time.Duration
-}
-
-// NewWeightCache creates a new cache with the specified TTL
-func NewWeightCache(ttl time.Duration) *WeightCache {
-	return &WeightCache{
-		cache: make(map[string]*ReturnedValueStandard),
-		ttl:   ttl,
-	}
-}
-
-// Get retrieves a cached result if it exists
-func (wc *WeightCache) Get(key string) (*ReturnedValueStandard, bool) {
-	wc.mu.RLock()
-	defer wc.mu.RUnlock()
-	result, found := wc.cache[key]
-	return result, found
-}
-
-// Set stores a calculation result in the cache
-func (wc *WeightCache) Set(key string, value *ReturnedValueStandard) {
-	wc.mu.Lock()
-	defer wc.mu.Unlock()
-	wc.cache[key] = value
-	
-	// Set up automatic expiration if TTL is positive
-	if wc.ttl > 0 {
-		go func(k string) {
-			time.Sleep(wc.ttl)
-			wc.mu.Lock()
-			delete(wc.cache, k)
-			wc.mu.Unlock()
-		}(key)
-	}
-}
-
-// Clear empties the cache
-func (wc *WeightCache) Clear() {
-	wc.mu.Lock()
-	defer wc.mu.Unlock()
-	wc.cache = make(map[string]*ReturnedValueStandard)
-}
-
-// Global cache instance
-var weightCache *WeightCache
-
 // Routes function that sets up the initial Chi Router
 func Routes() *chi.Mux {
 	router := chi.NewRouter()
 
 	corsMiddleware := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders: []string{"Link"},
+		AllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", []string{"*"}),
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Admin-Key"},
+		ExposedHeaders: []string{"Link", "X-Cache", "ETag", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"},
 		MaxAge:         300, // Maximum value not ignored by any of major browsers
 	})
 
 	router.Use(corsMiddleware.Handler)
+	if getEnvBool("TRUST_PROXY_HEADERS", false) {
+		// Take the client address from X-Forwarded-For or X-Real-IP, for rate limits and logs
+		router.Use(middleware.RealIP)
+	}
 	router.Use(
-		render.SetContentType(render.ContentTypeJSON),
-		middleware.Logger,
+		Metrics,
+		middleware.RequestID, // Before Tracing and RequestLogger, which record the ID
+		Tracing,
+		RequestLogger,
 		middleware.RedirectSlashes,
 		middleware.Recoverer,
-		middleware.RequestID,
 	)
 
 	// Health check endpoints
 	router.Get("/health", HealthCheck)
 	router.Get("/status", HealthCheck)
 	router.Get("/", HealthCheck)
-	
+
 	router.Get("/docs/*", httpSwagger.Handler())
+	router.Handle("/metrics", promhttp.Handler())
 	return router
 }
 
-// WeightAmounts is a translation for keynames in amounts (weight per single plate)
-var WeightAmounts = map[string]float32{
-	"Hundos":         100,
-	"FortyFives":     45,
-	"ThirtyFives":    35,
-	"TwentyFives":    25,
-	"Tens":           10,
-	"Fives":          5,
-	"TwoDotFives":    2.5,
-	"OneDotTwoFives": 1.25,
+func main() {
+	// Any arguments select a CLI command; a bare `gorack` runs the server
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	serve()
 }
 
-func main() {
-	// Initialize cache with TTL from environment or 1 hour if not set
-	cacheTTL := getEnvDuration("CACHE_TTL", 1*time.Hour)
-	weightCache = NewWeightCache(cacheTTL)
-	
+// serve starts the HTTP API server.
+func serve() {
+	if err := setupLogging(os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Initialize the cache, bounded so unique POST bodies can't grow it forever
+	weightCache = NewWeightCache(CacheConfig{
+		TTL:           getEnvDuration("CACHE_TTL", 1*time.Hour),
+		MaxEntries:    getEnvInt("CACHE_MAX_ENTRIES", 10000),
+		MaxBytes:      getEnvInt("CACHE_MAX_BYTES", 16<<20),
+		SweepInterval: getEnvDuration("CACHE_SWEEP_INTERVAL", 1*time.Minute),
+	})
+	prometheus.MustRegister(newCacheCollector(weightCache))
+
+	if err := setupTracing(context.Background()); err != nil {
+		fatal("Setting up tracing failed", "err", err)
+	}
+
+	limits, err := ParseRateLimits(getEnv("RATE_LIMITS", defaultRateLimits))
+	if err != nil {
+		fatal("Invalid RATE_LIMITS", "err", err)
+	}
+	rateLimiter = NewRateLimiter(RateLimitConfig{
+		Limits:        limits,
+		DailyQuota:    getEnvInt("DAILY_QUOTA", 0),
+		SweepInterval: 1 * time.Minute,
+	})
+
+	// Answer GET /rack with the default plates from memory
+	defaultTable.Build(rack.AssumeDefaults())
+
+	// Open the persistent store for inventory profiles
+	store, err := OpenStore(getEnv("DATA_PATH", "data/gorack.json"))
+	if err != nil {
+		fatal("Opening data store failed", "err", err)
+	}
+	dataStore = store
+
 	router := Routes()
 
 	router.Route("/v1/api", func(r chi.Router) {
-		r.Post("/rack", RackEmPost)
-		r.Get("/rack", RackEmGet)
+		r.Use(Authenticate, rateLimiter.Limit)
+
+		r.Group(func(r chi.Router) {
+			// Calculations stay open unless the server is told to require keys
+			if getEnvBool("AUTH_REQUIRED", false) {
+				r.Use(RequireScope(ScopeRead))
+			}
+			r.Post("/rack", RackEmPost)
+			r.Get("/rack", RackEmGet)
+			r.Post("/rack/batch", RackEmBatch)
+			r.Get("/rack/chart", RackEmChart)
+			r.Post("/sessions/pdf", RackEmSessionPDF)
+		})
+
+		r.Route("/users", UserRoutes)
+		r.Route("/keys", APIKeyRoutes)
+		r.Route("/inventories", InventoryRoutes)
+		r.Route("/workouts", WorkoutRoutes)
+		r.Route("/admin", AdminRoutes)
 	})
 
+	// GraphQL sits beside the REST routes and follows the same auth rules
+	router.Group(func(r chi.Router) {
+		r.Use(Authenticate, rateLimiter.Limit)
+		if getEnvBool("AUTH_REQUIRED", false) {
+			r.Use(RequireScope(ScopeRead))
+		}
+		r.Get("/graphql", GraphQLHandler)
+		r.Post("/graphql", GraphQLHandler)
+	})
+
+	// Browser UI, signed in with an API key cookie rather than a header and
+	// rate limited like the API
+	router.Route("/ui", UIRoutes)
+
 	walkFunc := func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
-		log.Printf("%s %s\n", method, route)
+		slog.Debug("Route", "method", method, "route", route)
 		return nil
 	}
 	if err := chi.Walk(router, walkFunc); err != nil {
-		log.Panicf("Logging err: %s\n", err.Error())
+		fatal("Listing routes failed", "err", err)
+	}
+
+	// gRPC shares the solvers, cache and store, on its own port
+	if getEnvBool("GRPC_ENABLED", true) {
+		go serveGRPC(getEnv("GRPC_PORT", "9090"))
 	}
 
 	port := getEnv("API_PORT", "8080")
-	log.Printf("Starting server on :%s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	slog.Info("Starting server", "port", port)
+	fatal("Server stopped", "err", http.ListenAndServe(":"+port, router))
 }
 
-
-
 // RackEmPost godoc
 // @Summary      Calculate plates with custom plate availability
-// @Description  Returns an optimal plate configuration for a given target weight with custom available plates
+// @Description  Returns an optimal plate configuration for a given target weight with custom available plates. The body and response can be JSON, XML, YAML or MessagePack (see Content-Type and Accept).
 // @Tags         Rack
-// @Accept       json
-// @Produce      json
-// @Param        request    body     RackInputStandard  true  "Desired weight and available plates"
-// @Success      200  {object}  ReturnedValueStandard
+// @Accept       json,xml,application/yaml,application/msgpack
+// @Produce      json,xml,application/yaml,application/msgpack
+// @Param        request    body     RackRequest  true  "Desired weight and available plates"
+// @Param        strategy   query    string       false  "Solver strategy, used when the body doesn't name one"
+// @Param        explain    query    bool         false  "Include the solver's decision trace"
+// @Success      200  {object}  rack.ReturnedValueStandard
 // @Failure      400  {object}  ErrResponse
+// @Failure      429  {object}  ErrResponse
 // @Failure      500  {object}  ErrResponse
 // @Router       /rack [post]
 func RackEmPost(w http.ResponseWriter, r *http.Request) {
-	input := &RackInputStandard{}
+	request := &RackRequest{}
 
-	if err := render.Bind(r, input); err != nil {
+	ctx, span := tracer.Start(r.Context(), "bind")
+	err := render.Bind(r.WithContext(ctx), request)
+	endSpan(span, err)
+	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
+	input := &request.RackInputStandard
+
 	// Generate cache key for this specific input
 	cacheKey := generateCacheKey(input)
-	
-	// Check cache first
-	if cachedResult, found := weightCache.Get(cacheKey); found {
-		render.JSON(w, r, cachedResult)
-		return
-	}
 
-	// Cache miss, calculate and store
-	results, err := CalculateWeight(input)
+	results, hit, err := calculate(r.Context(), cacheKey, input, request.Explain)
 	if err != nil {
-		log.Printf("Error calculating weight for POST: %v\nInput: %+v\n", err, input)
-		render.Render(w, r, ErrInternal())
+		slog.ErrorContext(r.Context(), "Calculating weight failed", "err", err, "input", input)
+		render.Render(w, r, ErrCalculation(err))
 		return
 	}
-	
-	// Store in cache
-	weightCache.Set(cacheKey, results)
-	
-	render.JSON(w, r, results)
+	setCacheHeader(w, hit)
+	annotateRequest(r.Context(), input, hit)
+
+	render.Respond(w, r, results)
 }
 
 // RackEmGet godoc
 // @Summary      Calculate plates using default plate availability
-// @Description  Returns an optimal plate configuration for a given target weight
+// @Description  Returns an optimal plate configuration for a given target weight. The response format follows the Accept header: JSON, XML, YAML or MessagePack.
 // @Tags         Rack
-// @Accept       json
-// @Produce      json
-// @Param        weight    query     int  true  "Desired weight in pounds"
-// @Success      200  {object}  ReturnedValueStandard
+// @Accept       json,xml,application/yaml,application/msgpack
+// @Produce      json,xml,application/yaml,application/msgpack
+// @Param        weight     query     int     true   "Desired weight in pounds"
+// @Param        inventory  query     string  false  "Inventory profile ID to use instead of the default plates"
+// @Param        strategy   query     string  false  "Solver strategy: greedy (default), exact, fewest-plates, fewest-small-plates or keep-big-free"
+// @Param        explain    query     bool    false  "Include the solver's decision trace"
+// @Param        If-None-Match  header  string  false  "ETag of a cached copy; a match returns 304"
+// @Success      200  {object}  rack.ReturnedValueStandard
+// @Header       200  {string}  ETag           "Strong validator for this result in this format"
+// @Header       200  {string}  Cache-Control  "public with max-age CACHE_TTL for the default plates, private, no-cache with an inventory"
+// @Success      304  "The cached copy named in If-None-Match is still current"
 // @Failure      400  {object}  ErrResponse
+// @Failure      429  {object}  ErrResponse
 // @Failure      500  {object}  ErrResponse
 // @Router       /rack [get]
 func RackEmGet(w http.ResponseWriter, r *http.Request) {
-	inputWithDefaults := AssumeDefaults()
+	inputWithDefaults := rack.AssumeDefaults()
 	weightStr := r.URL.Query().Get("weight")
 	if weightStr == "" {
 		render.Render(w, r, ErrInvalidRequest(errors.New("query parameter 'weight' is required")))
@@ -229,35 +285,63 @@ func RackEmGet(w http.ResponseWriter, r *http.Request) {
 	}
 	inputWithDefaults.DesiredWeight = int(weight)
 
+	// Swap the default plates for a saved inventory profile when one is requested
+	inventoryID := r.URL.Query().Get("inventory")
+	if inventoryID != "" {
+		inv, err := lookupInventory(r.Context(), inventoryID)
+		if err != nil {
+			render.Render(w, r, ErrNotFound(errors.New("inventory not found")))
+			return
+		}
+		inputWithDefaults.BarWeight = 0
+		inv.Apply(&inputWithDefaults)
+	}
+
 	// Validate DesiredWeight against BarWeight for GET requests
 	if inputWithDefaults.DesiredWeight <= inputWithDefaults.BarWeight {
 		render.Render(w, r, ErrInvalidRequest(errors.New("desired weight must be greater than bar weight")))
 		return
 	}
-
-	// Check cache for GET request with standard plates
-	cacheKey := fmt.Sprintf("get:%d", inputWithDefaults.DesiredWeight)
-	if cachedResult, found := weightCache.Get(cacheKey); found {
-		render.JSON(w, r, cachedResult)
+	if inputWithDefaults.Strategy, err = parseStrategy(r.URL.Query().Get("strategy")); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	results, calcErr := CalculateWeight(&inputWithDefaults)
+	// Standard plates come from the lookup table or cache. Inventory plates
+	// can change at any time, so key on the plates themselves
+	explain := r.URL.Query().Get("explain") == "true"
+	var results *rack.ReturnedValueStandard
+	var hit bool
+	var calcErr error
+	if inventoryID == "" {
+		results, hit, calcErr = calculateDefault(r.Context(), &inputWithDefaults, explain)
+	} else {
+		results, hit, calcErr = calculate(r.Context(), generateCacheKey(&inputWithDefaults), &inputWithDefaults, explain)
+	}
 	if calcErr != nil {
-		log.Printf("Error calculating weight for GET: %v\nInput: %+v\n", calcErr, inputWithDefaults)
-		render.Render(w, r, ErrInternal())
+		slog.ErrorContext(r.Context(), "Calculating weight failed", "err", calcErr, "input", inputWithDefaults)
+		render.Render(w, r, ErrCalculation(calcErr))
+		return
+	}
+	setCacheHeader(w, hit)
+	annotateRequest(r.Context(), &inputWithDefaults, hit)
+	// The ETag is a digest of the result, so a conditional request is still
+	// calculated (usually from the table or cache) and a 304 only saves the body
+	if notModified(w, r, rackETag(r, results), rackCacheControl(r, inventoryID != "")) {
 		return
 	}
-	
-	// Store in cache
-	weightCache.Set(cacheKey, results)
-	
-	render.JSON(w, r, results)
+
+	render.Respond(w, r, results)
 }
 
 // generateCacheKey creates a unique key for caching based on input parameters
-func generateCacheKey(input *RackInputStandard) string {
-	return fmt.Sprintf("post:bar=%d:desired=%d:h=%d:45=%d:35=%d:25=%d:10=%d:5=%d:2.5=%d:1.25=%d",
+func generateCacheKey(input *rack.RackInputStandard) string {
+	strategy := input.Strategy
+	if strategy == "" {
+		strategy = rack.DefaultStrategy
+	}
+	return fmt.Sprintf("post:%s:bar=%d:desired=%d:h=%d:45=%d:35=%d:25=%d:10=%d:5=%d:2.5=%d:1.25=%d",
+		strategy,
 		input.BarWeight,
 		input.DesiredWeight,
 		input.Hundos,
@@ -271,160 +355,151 @@ func generateCacheKey(input *RackInputStandard) string {
 	)
 }
 
-// CalculateWeight is the core logic for calculating plates needed.
-// Input represents available plates. Output represents plates to use.
-func CalculateWeight(inputAvailablePlates *RackInputStandard) (*ReturnedValueStandard, error) {
-	platesToUse := map[string]int{} // Stores count of each plate type (pair) to load
-	currentBarWeight := inputAvailablePlates.BarWeight
-	if currentBarWeight < 0 { // Ensure bar weight is not negative
-	    currentBarWeight = 0
-    }
-	
-	leftOver := inputAvailablePlates.DesiredWeight - currentBarWeight
-	achievedWeight := currentBarWeight
-
-	// Create a mutable copy of the input available plates for deduction during calculation
-	currentAvailablePlates := *inputAvailablePlates
-
-	// Define order of plates to try, from heaviest to lightest.
-	orderedPlateNames := []string{
-		"Hundos", "FortyFives", "ThirtyFives", "TwentyFives",
-		"Tens", "Fives", "TwoDotFives", "OneDotTwoFives",
-	}
-
-	for leftOver > 0 {
-		foundPlateInIteration := false
-		for _, plateName := range orderedPlateNames {
-			
-			var plateAvailableCount int
-			val := reflect.ValueOf(&currentAvailablePlates).Elem()
-			fieldVal := val.FieldByName(plateName)
-			if fieldVal.IsValid() {
-				plateAvailableCount = int(fieldVal.Int())
-			} else {
-				// This should not happen if orderedPlateNames matches RackInputStandard fields
-				return nil, errors.New("internal error: plate name mismatch: " + plateName)
-			}
+// calculateCached returns the cached result for cacheKey, calculating and
+// storing it on a miss. Identical requests that miss at the same time share
+// one calculation. hit reports whether the result came from the cache.
+func calculateCached(ctx context.Context, cacheKey string, input *rack.RackInputStandard) (result *rack.ReturnedValueStandard, hit bool, err error) {
+	ctx, span := tracer.Start(ctx, "cache.lookup", trace.WithAttributes(attribute.String("cache.key", cacheKey)))
+	result, hit, err = weightCache.GetOrCalculate(cacheKey, func() (*rack.ReturnedValueStandard, error) {
+		return observeSolver(ctx, input, false)
+	})
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+	endSpan(span, err)
+	return result, hit, err
+}
 
+// calculate runs a calculation through the cache, or uncached with the
+// solver's decision trace when explain is set.
+func calculate(ctx context.Context, cacheKey string, input *rack.RackInputStandard, explain bool) (result *rack.ReturnedValueStandard, hit bool, err error) {
+	if explain {
+		result, err = observeSolver(ctx, input, true)
+		return result, false, err
+	}
+	return calculateCached(ctx, cacheKey, input)
+}
 
-			if plateAvailableCount == 0 {
-				continue
-			}
+// calculateDefault runs a calculation for the default bar and plates, from
+// the lookup table when it has the answer and through the cache otherwise.
+func calculateDefault(ctx context.Context, input *rack.RackInputStandard, explain bool) (result *rack.ReturnedValueStandard, hit bool, err error) {
+	if !explain {
+		_, span := tracer.Start(ctx, "table.lookup")
+		result, found := defaultTable.Lookup(input.Strategy, input.DesiredWeight)
+		span.SetAttributes(attribute.Bool("cache.hit", found))
+		span.End()
+		if found {
+			weightCache.CountTableHit()
+			return result, true, nil
+		}
+	}
+	return calculate(ctx, defaultCacheKey(input), input, explain)
+}
 
-			plateWeightPerSingle, ok := WeightAmounts[plateName]
-			if !ok {
-				return nil, errors.New("internal error: weight definition missing for " + plateName)
-			}
-			weightOfPair := int(plateWeightPerSingle * 2)
-
-			if weightOfPair > 0 && weightOfPair <= leftOver {
-				leftOver -= weightOfPair
-				platesToUse[plateName]++
-				achievedWeight += weightOfPair
-				currentAvailablePlates.DecreaseWeight(plateName)
-				foundPlateInIteration = true
-				break // Greedily take the heaviest possible, then restart outer loop for next heaviest
-			}
+// defaultCacheKey is the cache key for a GET /rack calculation with the
+// default plates, which only vary by strategy and weight.
+func defaultCacheKey(input *rack.RackInputStandard) string {
+	return fmt.Sprintf("get:%s:%d", input.Strategy, input.DesiredWeight)
+}
+
+// setCacheHeader marks a response as served from the cache or not, for
+// debugging stale results.
+func setCacheHeader(w http.ResponseWriter, hit bool) {
+	if hit {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+}
+
+// RackQuery is a calculation from a transport other than REST. Without
+// Plates it follows RackEmGet and uses the default plates; with them it
+// follows RackEmPost.
+type RackQuery struct {
+	Weight    int
+	Plates    *rack.RackInputStandard // Available plates, nil for the defaults
+	Inventory string                  // Inventory profile ID, replaces the plates
+	Strategy  string
+	Explain   bool
+}
+
+// Calculate validates and runs the query through the same cache as the REST
+// handlers. Errors are *ErrResponse so each transport can map the status.
+func (q *RackQuery) Calculate(ctx context.Context) (*rack.ReturnedValueStandard, *ErrResponse) {
+	input := rack.AssumeDefaults()
+	if q.Plates != nil {
+		input = *q.Plates
+	}
+	if q.Inventory != "" {
+		inv, err := lookupInventory(ctx, q.Inventory)
+		if err != nil {
+			return nil, ErrNotFound(errors.New("inventory not found")).(*ErrResponse)
 		}
-		if !foundPlateInIteration {
-			break // No suitable plate could be added in this pass
+		if q.Plates == nil {
+			input.BarWeight = 0 // Use the inventory's bar rather than the default one
 		}
+		inv.Apply(&input)
 	}
+	input.DesiredWeight = q.Weight
+	input.Strategy = q.Strategy
 
-	// Prepare the output struct containing plates to use
-	outputPlates := RackInputStandard{
-		BarWeight:     currentBarWeight,
-		DesiredWeight: inputAvailablePlates.DesiredWeight,
+	if err := input.Validate(); err != nil {
+		return nil, ErrInvalidRequest(err).(*ErrResponse)
 	}
-	// Populate outputPlates with the counts from platesToUse map
-	decoderConfig := &mapstructure.DecoderConfig{
-		Result:   &outputPlates,
-		TagName:  "json",
-		Squash:   true,
+	if input.Strategy == "" {
+		input.Strategy = rack.DefaultStrategy
 	}
-	decoder, err := mapstructure.NewDecoder(decoderConfig)
-	if err != nil {
-	    log.Printf("Error creating mapstructure decoder: %v", err)
-		return nil, errors.New("internal error creating decoder")
+
+	// Same lookup table and cache keys as RackEmGet and RackEmPost
+	var result *rack.ReturnedValueStandard
+	var err error
+	if q.Plates == nil && q.Inventory == "" {
+		result, _, err = calculateDefault(ctx, &input, q.Explain)
+	} else {
+		result, _, err = calculate(ctx, generateCacheKey(&input), &input, q.Explain)
 	}
-	if err := decoder.Decode(platesToUse); err != nil {
-	    log.Printf("Error decoding plates map to struct: %v", err)
-		return nil, errors.New("internal error decoding result")
+	if err != nil {
+		slog.ErrorContext(ctx, "Calculating weight failed", "err", err, "input", input)
+		return nil, ErrCalculation(err).(*ErrResponse)
 	}
+	return result, nil
+}
 
-
-	return &ReturnedValueStandard{
-		RackInputStandard: &outputPlates,
-		AchievedWeight:    achievedWeight,
-		Message:           "You got this!",
-	}, nil
+// parseStrategy checks a strategy name, returning the default strategy for an empty one.
+func parseStrategy(name string) (string, error) {
+	if name == "" {
+		return rack.DefaultStrategy, nil
+	}
+	if _, err := rack.Lookup(name); err != nil {
+		return "", err
+	}
+	return name, nil
 }
 
 /* Models */
 
-// RackInputStandard defines the structure for API input (available plates)
-// and also for the plates to be used in the output.
-// Plate counts are number of PAIRS.
-type RackInputStandard struct {
-	BarWeight      int `json:"barWeight,omitempty"`
-	Hundos         int `json:"hundreds,omitempty"` // JSON tag "hundreds" for API compatibility
-	FortyFives     int `json:"fortyFives,omitempty"`
-	ThirtyFives    int `json:"thirtyFives,omitempty"`
-	TwentyFives    int `json:"twentyFives,omitempty"`
-	Tens           int `json:"tens,omitempty"`
-	Fives          int `json:"fives,omitempty"`
-	TwoDotFives    int `json:"twoDotFives,omitempty"`
-	OneDotTwoFives int `json:"oneDotTwoFives,omitempty"`
-	DesiredWeight  int `json:"desiredWeight"` // Required in input
+// RackRequest is the POST /rack payload: a rack.RackInputStandard plus an
+// optional saved inventory to take the plates from and the explain flag.
+type RackRequest struct {
+	rack.RackInputStandard
+	Inventory string `json:"inventory,omitempty" xml:"inventory,omitempty"` // Optional inventory profile ID, replaces listed plates
+	Explain   bool   `json:"explain,omitempty" xml:"explain,omitempty"`     // Include the solver's decision trace
 }
 
-// Bind is a method on RackInputStandard to process and validate the request payload.
-func (ris *RackInputStandard) Bind(r *http.Request) error {
-	if ris.BarWeight == 0 { // If not provided, default to standard Olympic bar
-		ris.BarWeight = AssumeDefaults().BarWeight
-	}
-    if ris.BarWeight < 0 {
-        return errors.New("bar weight cannot be negative")
-    }
-	if ris.DesiredWeight == 0 {
-		return errors.New("a valid desired weight must be provided")
+// Bind resolves the inventory, if any, and validates the request payload.
+func (rr *RackRequest) Bind(r *http.Request) error {
+	if rr.Inventory != "" {
+		inv, err := lookupInventory(r.Context(), rr.Inventory)
+		if err != nil {
+			return errors.New("inventory not found")
+		}
+		inv.Apply(&rr.RackInputStandard)
 	}
-	if ris.DesiredWeight <= ris.BarWeight {
-		return errors.New("desired weight must be greater than bar weight")
+	if rr.Strategy == "" {
+		rr.Strategy = r.URL.Query().Get("strategy")
 	}
-	// Plate counts (Hundos, FortyFives, etc.) default to 0 if not in payload,
-	// meaning "0 pairs available" for POST requests.
-	return nil
-}
-
-// DecreaseWeight reduces the count of a specific plate type.
-// This is called on the *copy* of available plates during calculation.
-func (ris *RackInputStandard) DecreaseWeight(plateName string) {
-	switch plateName {
-	case "Hundos":
-		ris.Hundos--
-	case "FortyFives":
-		ris.FortyFives--
-	case "ThirtyFives":
-		ris.ThirtyFives--
-	case "TwentyFives":
-		ris.TwentyFives--
-	case "Tens":
-		ris.Tens--
-	case "Fives":
-		ris.Fives--
-	case "TwoDotFives":
-		ris.TwoDotFives--
-	case "OneDotTwoFives":
-		ris.OneDotTwoFives--
+	if r.URL.Query().Get("explain") == "true" {
+		rr.Explain = true
 	}
-}
-
-// ReturnedValueStandard is the structure of the JSON response.
-type ReturnedValueStandard struct {
-	*RackInputStandard        // Embeds the plates *to use* for the lift
-	AchievedWeight     int    `json:"achievedWeight"`
-	Message            string `json:"message,omitempty"`
+	return rr.Validate()
 }
 
 // HealthCheck godoc
@@ -465,17 +540,54 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+// getEnvInt retrieves an integer environment variable or returns a fallback value.
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
+
+// getEnvBool retrieves a boolean environment variable or returns a fallback value.
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// getEnvList retrieves a comma-separated environment variable or returns a fallback value.
+func getEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // ErrResponse is a generic renderer for API error responses.
 type ErrResponse struct {
-	Err            error  `json:"-"` // Low-level runtime error (not exposed to client)
-	HTTPStatusCode int    `json:"-"` // HTTP response status code
-	StatusText     string `json:"status"`          // User-level status message
-	AppCode        int64  `json:"code,omitempty"`  // Application-specific error code
-	ErrorText      string `json:"error,omitempty"` // Application-level error message for debugging
+	XMLName        xml.Name `json:"-" xml:"errorResponse"`
+	Err            error    `json:"-" xml:"-"`                             // Low-level runtime error (not exposed to client)
+	HTTPStatusCode int      `json:"-" xml:"-"`                             // HTTP response status code
+	StatusText     string   `json:"status" xml:"status"`                   // User-level status message
+	AppCode        int64    `json:"code,omitempty" xml:"code,omitempty"`   // Application-specific error code
+	ErrorText      string   `json:"error,omitempty" xml:"error,omitempty"` // Application-level error message for debugging
 }
 
 // Render sets the HTTP status code for the error response.
 func (e *ErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	noteRequestError(r.Context(), e.ErrorText)
 	render.Status(r, e.HTTPStatusCode)
 	return nil
 }
@@ -490,6 +602,55 @@ func ErrInvalidRequest(err error) render.Renderer {
 	}
 }
 
+// ErrUnauthorized creates a standardized "401 Unauthorized" response.
+func ErrUnauthorized(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusUnauthorized,
+		StatusText:     "Unauthorized.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrForbidden creates a standardized "403 Forbidden" response.
+func ErrForbidden(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusForbidden,
+		StatusText:     "Forbidden.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrNotFound creates a standardized "404 Not Found" response.
+func ErrNotFound(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusNotFound,
+		StatusText:     "Resource not found.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrCalculation renders a failed calculation: a 400 when the request asked
+// for more than a solver will do, a 500 otherwise.
+func ErrCalculation(err error) render.Renderer {
+	if errors.Is(err, rack.ErrSearchTooLarge) {
+		return ErrInvalidRequest(err)
+	}
+	return ErrInternal()
+}
+
+// ErrTooManyRequests creates a standardized "429 Too Many Requests" response.
+func ErrTooManyRequests(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusTooManyRequests,
+		StatusText:     "Too many requests.",
+		ErrorText:      err.Error(),
+	}
+}
+
 // ErrInternal creates a standardized "500 Internal Server Error" response.
 func ErrInternal() render.Renderer {
 	return &ErrResponse{
@@ -499,19 +660,3 @@ func ErrInternal() render.Renderer {
 		ErrorText:      "An unexpected error occurred. Please try again later.",
 	}
 }
-
-// AssumeDefaults provides a default set of available plates and bar weight.
-// Used for GET requests where the user doesn't specify their available equipment.
-func AssumeDefaults() RackInputStandard {
-	return RackInputStandard{
-		BarWeight:      45,  // Standard Olympic bar weight in lbs
-		Hundos:         10, 
-		FortyFives:     10,
-		ThirtyFives:    10,
-		TwentyFives:    10,
-		Tens:           10,
-		Fives:          10,
-		TwoDotFives:    10,
-		OneDotTwoFives: 10,
-	}
-}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useTestStore points dataStore at an empty store in a temporary directory
+// for the length of the test.
+func useTestStore(t *testing.T) {
+	t.Helper()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "gorack.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	previous := dataStore
+	dataStore = store
+	t.Cleanup(func() { dataStore = previous })
+}
+
+// useTestCache points weightCache at a fresh cache for the length of the test.
+func useTestCache(t *testing.T, config CacheConfig) *WeightCache {
+	t.Helper()
+	cache := NewWeightCache(config)
+	previous := weightCache
+	weightCache = cache
+	t.Cleanup(func() {
+		cache.Close()
+		weightCache = previous
+	})
+	return cache
+}
+
+// newTestUser saves a user and returns a token for a key with scopes.
+func newTestUser(t *testing.T, name string, scopes ...string) (*User, string) {
+	t.Helper()
+	id, err := newID()
+	if err != nil {
+		t.Fatalf("newID: %v", err)
+	}
+	user := &User{ID: id, Name: name, CreatedAt: time.Now().UTC()}
+	if err := dataStore.PutUser(user); err != nil {
+		t.Fatalf("PutUser: %v", err)
+	}
+	_, token, err := issueAPIKey(user.ID, "test", scopes)
+	if err != nil {
+		t.Fatalf("issueAPIKey: %v", err)
+	}
+	return user, token
+}
+
+// serveRequest sends a request through handler. A non-empty token is sent
+// as a bearer key, and headers are name/value pairs.
+func serveRequest(handler http.Handler, method, target, body, token string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeError reads the ErrResponse in a response body.
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) ErrResponse {
+	t.Helper()
+	var response ErrResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding error response %q: %v", rec.Body.String(), err)
+	}
+	return response
+}
+
+// fakeClock is a clock tests move by hand. It's safe to read from the
+// background goroutines that sweep caches and limiters.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// eventually polls condition until it holds or a few seconds pass.
+func eventually(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if condition() {
+			return
+		}
+	}
+	t.Fatal(message)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/pachev/gorack/rack"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// HTTP and solver metrics. The default registry also carries the Go runtime
+// and process collectors, and serve adds the cache's counters.
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorack_http_requests_total",
+		Help: "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorack_http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	solverDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorack_solver_duration_seconds",
+		Help:    "Time spent in a solver per calculation, by strategy. Cache and lookup table hits aren't solved.",
+		Buckets: prometheus.ExponentialBuckets(0.000001, 4, 11), // 1µs to about 1s
+	}, []string{"strategy"})
+
+	solverUnreachable = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorack_solver_unreachable_total",
+		Help: "Calculations whose target couldn't be loaded exactly with the available plates, by strategy.",
+	}, []string{"strategy"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorack_grpc_requests_total",
+		Help: "gRPC calls by full method name and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorack_grpc_request_duration_seconds",
+		Help:    "gRPC call latency by full method name and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorack_rate_limited_total",
+		Help: "Requests turned away with 429, by route pattern and reason: rate or quota.",
+	}, []string{"route", "reason"})
+)
+
+// Metrics records request counts and latencies. Requests are labelled with
+// their chi route pattern rather than the path, so IDs and query strings
+// don't multiply the series.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route, code := routePattern(r), strconv.Itoa(responseStatus(ww))
+		httpRequests.WithLabelValues(r.Method, route, code).Inc()
+		httpDuration.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+// metricsRPC is Metrics for the gRPC service, labelled with the full method
+// name in place of the route pattern.
+func metricsRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err).String()
+	grpcRequests.WithLabelValues(info.FullMethod, code).Inc()
+	grpcDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// routePattern is the chi route pattern that served r, or "unmatched".
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return "unmatched"
+}
+
+// responseStatus is the status code written to ww.
+func responseStatus(ww middleware.WrapResponseWriter) int {
+	if status := ww.Status(); status != 0 {
+		return status
+	}
+	return http.StatusOK // Nothing was written
+}
+
+// observeSolver runs rack.CalculateWeight, or rack.Explain when explain is
+// set, in its own span, recording how long it took and whether the target
+// was reached.
+func observeSolver(ctx context.Context, input *rack.RackInputStandard, explain bool) (*rack.ReturnedValueStandard, error) {
+	solve, name := rack.CalculateWeight, "rack.CalculateWeight"
+	if explain {
+		solve, name = rack.Explain, "rack.Explain"
+	}
+	_, span := tracer.Start(ctx, name, trace.WithAttributes(rackAttributes(input)...))
+	strategy := input.Strategy
+	if strategy == "" {
+		strategy = rack.DefaultStrategy
+	}
+	start := time.Now()
+	result, err := solve(input)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("rack.achieved_weight", result.AchievedWeight))
+	span.End()
+	solverDuration.WithLabelValues(strategy).Observe(time.Since(start).Seconds())
+	if result.AchievedWeight != input.DesiredWeight {
+		solverUnreachable.WithLabelValues(strategy).Inc()
+	}
+	return result, nil
+}
+
+// cacheCollector exports a WeightCache's Stats on every scrape.
+type cacheCollector struct {
+	cache *WeightCache
+
+	entries, bytes                       *prometheus.Desc
+	hits, misses, evictions, expirations *prometheus.Desc
+	calculations, coalesced, tableHits   *prometheus.Desc
+}
+
+func newCacheCollector(cache *WeightCache) *cacheCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("gorack_cache_"+name, help, nil, nil)
+	}
+	return &cacheCollector{
+		cache:        cache,
+		entries:      desc("entries", "Results in the cache, including expired ones not yet swept."),
+		bytes:        desc("bytes", "Approximate memory held by cached results."),
+		hits:         desc("hits_total", "Cache lookups that found a fresh result, including loading table hits."),
+		misses:       desc("misses_total", "Cache lookups that found nothing or an expired result."),
+		evictions:    desc("evictions_total", "Results removed to stay within CACHE_MAX_ENTRIES or CACHE_MAX_BYTES."),
+		expirations:  desc("expirations_total", "Results removed after CACHE_TTL."),
+		calculations: desc("calculations_total", "Solver runs after a cache miss."),
+		coalesced:    desc("coalesced_total", "Cache misses that waited for an identical calculation instead of running their own."),
+		tableHits:    desc("table_hits_total", "GET /rack lookups answered from the default plates' loading table."),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{c.entries, c.bytes, c.hits, c.misses, c.evictions, c.expirations, c.calculations, c.coalesced, c.tableHits} {
+		ch <- desc
+	}
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(stats.Bytes))
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(stats.Expirations))
+	ch <- prometheus.MustNewConstMetric(c.calculations, prometheus.CounterValue, float64(stats.Calculations))
+	ch <- prometheus.MustNewConstMetric(c.coalesced, prometheus.CounterValue, float64(stats.Coalesced))
+	ch <- prometheus.MustNewConstMetric(c.tableHits, prometheus.CounterValue, float64(stats.TableHits))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/vmihailenco/msgpack/v5"
+	"sigs.k8s.io/yaml"
+)
+
+// Response and request body formats beyond the JSON and XML render knows.
+const (
+	contentTypeYAML    = "application/yaml"
+	contentTypeMsgPack = "application/msgpack"
+)
+
+// mediaTypes maps the media types clients send to the format used.
+var mediaTypes = map[string]string{
+	"application/json":        "application/json",
+	"application/xml":         "application/xml",
+	"text/xml":                "application/xml",
+	"application/yaml":        contentTypeYAML,
+	"application/x-yaml":      contentTypeYAML,
+	"text/yaml":               contentTypeYAML,
+	"application/msgpack":     contentTypeMsgPack,
+	"application/x-msgpack":   contentTypeMsgPack,
+	"application/vnd.msgpack": contentTypeMsgPack,
+}
+
+func init() {
+	// Swapped globally on purpose: everything that goes through
+	// render.Render, render.Respond and render.Bind is negotiated, so every
+	// ErrResponse on every route comes back in the format the client asked
+	// for, and every Bind payload can be sent as YAML or MessagePack.
+	// Handlers that call render.JSON directly always answer JSON.
+	render.Respond = negotiateResponse
+	render.Decode = negotiateDecode
+}
+
+// acceptedType is one media range from an Accept header.
+type acceptedType struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept reads an Accept header's media ranges, most preferred first.
+// Ranges with the same q-value keep the order they were listed in, and
+// ranges the client refuses with q=0 are dropped.
+func parseAccept(header string) []acceptedType {
+	var accepted []acceptedType
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	return accepted
+}
+
+// negotiatedFormat picks the response format from the Accept header. Only
+// the types the client ranks highest are considered: the first of those
+// with a format wins, and a wildcard or types without one get JSON. So a
+// browser, which ranks text/html above application/xml, gets JSON, as does
+// a request without an Accept header.
+func negotiatedFormat(r *http.Request) string {
+	accepted := parseAccept(r.Header.Get("Accept"))
+	for _, candidate := range accepted {
+		if candidate.q < accepted[0].q {
+			break
+		}
+		if format, ok := mediaTypes[candidate.mediaType]; ok {
+			return format
+		}
+	}
+	return "application/json"
+}
+
+// negotiateResponse is render.Respond with YAML and MessagePack added.
+func negotiateResponse(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Add("Vary", "Accept")
+	switch negotiatedFormat(r) {
+	case "application/xml":
+		render.XML(w, r, v)
+	case contentTypeYAML:
+		// Converted from JSON so fields keep their JSON names
+		body, err := yaml.Marshal(v)
+		writeEncoded(w, r, contentTypeYAML, body, err)
+	case contentTypeMsgPack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		err := enc.Encode(v)
+		writeEncoded(w, r, contentTypeMsgPack, buf.Bytes(), err)
+	default:
+		render.JSON(w, r, v)
+	}
+}
+
+// writeEncoded writes an encoded body with the status set by render.Status.
+// A value that can't be encoded is logged and answered with a JSON
+// ErrResponse instead.
+func writeEncoded(w http.ResponseWriter, r *http.Request, contentType string, body []byte, err error) {
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Encoding response failed", "content_type", contentType, "err", err)
+		errResponse := ErrInternal()
+		errResponse.Render(w, r)
+		render.JSON(w, r, errResponse)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	w.Write(body)
+}
+
+// negotiateDecode is render.Decode with YAML and MessagePack bodies added.
+// Any other Content-Type is read as JSON, as it was before negotiation.
+func negotiateDecode(r *http.Request, v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaTypes[mediaType] {
+	case contentTypeYAML:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return yaml.Unmarshal(body, v)
+	case contentTypeMsgPack:
+		dec := msgpack.NewDecoder(r.Body)
+		dec.SetCustomStructTag("json")
+		return dec.Decode(v)
+	case "application/xml":
+		return render.DecodeXML(r.Body, v)
+	default:
+		return render.DecodeJSON(r.Body, v)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/pachev/gorack/rack"
+	"github.com/vmihailenco/msgpack/v5"
+	"sigs.k8s.io/yaml"
+)
+
+func TestNegotiatedFormat(t *testing.T) {
+	tests := []struct {
+		accept, want string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/*", "application/json"},
+		{"application/json", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/xml", "application/xml"},
+		{"application/x-yaml", contentTypeYAML},
+		{"application/vnd.msgpack", contentTypeMsgPack},
+		{"text/csv", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json"},
+		{"text/html, application/xml", "application/xml"},
+		{"application/xml;q=0.9, */*;q=0.8", "application/xml"},
+		{"application/json;q=0.5, application/yaml", contentTypeYAML},
+		{"application/yaml;q=0.5, application/xml;q=0.5", contentTypeYAML},
+		{"application/yaml;q=0, application/xml;q=0.1", "application/xml"},
+		{"*/*;q=0.1, application/msgpack;q=0.2", contentTypeMsgPack},
+		{"application/xml;q=high", "application/json"},
+		{"not a media type", "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			if got := negotiatedFormat(req); got != tt.want {
+				t.Errorf("negotiatedFormat(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+// respond runs render.Respond with an Accept header and status.
+func respond(accept string, status int, v any) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	render.Status(req, status)
+	render.Respond(rec, req, v)
+	return rec
+}
+
+func TestNegotiateResponse(t *testing.T) {
+	input := rack.AssumeDefaults()
+	input.DesiredWeight = 225
+	result, err := rack.CalculateWeight(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		accept, contentType string
+		decode              func([]byte, any) error
+	}{
+		{"application/json", "application/json", json.Unmarshal},
+		{"application/xml", "application/xml", xml.Unmarshal},
+		{"application/yaml", contentTypeYAML, func(b []byte, v any) error { return yaml.Unmarshal(b, v) }},
+		{"application/msgpack", contentTypeMsgPack, func(b []byte, v any) error {
+			dec := msgpack.NewDecoder(bytes.NewReader(b))
+			dec.SetCustomStructTag("json")
+			return dec.Decode(v)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			rec := respond(tt.accept, http.StatusCreated, result)
+			if rec.Code != http.StatusCreated {
+				t.Errorf("status = %d, want 201", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+			decoded := rack.ReturnedValueStandard{RackInputStandard: &rack.RackInputStandard{}}
+			if err := tt.decode(rec.Body.Bytes(), &decoded); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body, err)
+			}
+			if decoded.AchievedWeight != 225 || decoded.FortyFives != 2 || decoded.DesiredWeight != 225 {
+				t.Errorf("decoded %+v %+v from %s", decoded, decoded.RackInputStandard, rec.Body)
+			}
+		})
+	}
+}
+
+func TestNegotiateResponseEncodingFailure(t *testing.T) {
+	for _, accept := range []string{"application/yaml", "application/msgpack"} {
+		t.Run(accept, func(t *testing.T) {
+			rec := respond(accept, http.StatusOK, map[string]any{"solve": func() {}})
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+				t.Errorf("Content-Type = %q, want JSON", got)
+			}
+			if got := decodeError(t, rec); got.StatusText != "Internal Server Error." {
+				t.Errorf("body = %s, want an ErrResponse", rec.Body)
+			}
+		})
+	}
+}
+
+func TestNegotiateDecode(t *testing.T) {
+	var msgpackBody bytes.Buffer
+	enc := msgpack.NewEncoder(&msgpackBody)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(map[string]any{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		contentType, body string
+	}{
+		{"application/json", `{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2}`},
+		{"", `{"desiredWeight": 185, "fortyFives": 1, "thirtyFives": 2}`},
+		{"application/xml", `<rack><desiredWeight>185</desiredWeight><fortyFives>1</fortyFives><thirtyFives>2</thirtyFives></rack>`},
+		{"application/x-yaml", "desiredWeight: 185\nfortyFives: 1\nthirtyFives: 2\n"},
+		{"application/msgpack", msgpackBody.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/api/rack", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			request := &RackRequest{}
+			if err := render.Bind(req, request); err != nil {
+				t.Fatalf("Bind: %v", err)
+			}
+			if request.DesiredWeight != 185 || request.FortyFives != 1 || request.ThirtyFives != 2 {
+				t.Errorf("decoded %+v", request.RackInputStandard)
+			}
+		})
+	}
+}
+
+// TestNegotiationIsGlobal pins down that the render override reaches every
+// route: errors follow Accept everywhere, while handlers that call
+// render.JSON keep answering JSON.
+func TestNegotiationIsGlobal(t *testing.T) {
+	useTestStore(t)
+	router := accountRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	rec := serveRequest(router, "GET", "/v1/api/inventories", "", "", "Accept", "application/yaml")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("Content-Type") != contentTypeYAML {
+		t.Fatalf("error response: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var errResponse ErrResponse
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &errResponse); err != nil || errResponse.ErrorText != "an API key is required" {
+		t.Errorf("YAML error = %+v, %v from %s", errResponse, err, rec.Body)
+	}
+
+	rec = serveRequest(router, "GET", "/v1/api/inventories", "", token, "Accept", "application/yaml")
+	if got := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || !strings.HasPrefix(got, "application/json") {
+		t.Errorf("render.JSON response: %d %q, want JSON", rec.Code, got)
+	}
+}
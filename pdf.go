@@ -0,0 +1,358 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/go-pdf/fpdf"
+	"github.com/pachev/gorack/rack"
+)
+
+// PDF layout, in millimetres on a portrait letter page.
+const (
+	pdfRowHeight     = 9.0
+	pdfDiagramWidth  = 42.0
+	pdfDiagramHeight = 7.0
+)
+
+// pdfColumn is a table column in a generated PDF.
+type pdfColumn struct {
+	title string
+	width float64
+	align string
+}
+
+// PlannedSet is one line of a planned session: a number of sets of an
+// exercise at a target weight.
+type PlannedSet struct {
+	Exercise string `json:"exercise"`
+	Sets     int    `json:"sets"`
+	Reps     int    `json:"reps"`
+	Weight   int    `json:"weight"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// SessionSheetRequest is a planned session to print as a PDF.
+type SessionSheetRequest struct {
+	Title     string       `json:"title,omitempty"`
+	Athlete   string       `json:"athlete,omitempty"`
+	Date      string       `json:"date,omitempty"` // YYYY-MM-DD, defaults to today
+	Inventory string       `json:"inventory,omitempty"`
+	BarWeight int          `json:"barWeight,omitempty"`
+	Notes     string       `json:"notes,omitempty"`
+	Sets      []PlannedSet `json:"sets"`
+
+	date   time.Time
+	plates rack.RackInputStandard
+}
+
+// Bind validates a planned session and resolves its plates.
+func (sr *SessionSheetRequest) Bind(r *http.Request) error {
+	if sr.Title == "" {
+		sr.Title = "Session"
+	}
+	if sr.Date == "" {
+		sr.date = time.Now()
+	} else {
+		date, err := time.Parse(dateLayout, sr.Date)
+		if err != nil {
+			return errors.New("date must be formatted as YYYY-MM-DD")
+		}
+		sr.date = date
+	}
+
+	if len(sr.Sets) == 0 {
+		return errors.New("at least one set is required")
+	}
+	// Every line is calculated and cached, so sheets are capped like batches
+	if maxSize := getEnvInt("BATCH_MAX_SIZE", 100); len(sr.Sets) > maxSize {
+		return fmt.Errorf("a session sheet cannot have more than %d sets", maxSize)
+	}
+	for i := range sr.Sets {
+		set := &sr.Sets[i]
+		set.Exercise = strings.TrimSpace(set.Exercise)
+		if set.Exercise == "" {
+			return fmt.Errorf("set %d: exercise is required", i+1)
+		}
+		if set.Sets == 0 {
+			set.Sets = 1
+		}
+		if set.Sets < 0 || set.Reps <= 0 {
+			return fmt.Errorf("set %d: sets and reps must be positive integers", i+1)
+		}
+		if set.Weight <= 0 {
+			return fmt.Errorf("set %d: weight must be a positive integer", i+1)
+		}
+		if set.Weight > rack.MaxSearchWeight {
+			return fmt.Errorf("set %d: weight cannot be more than %d", i+1, rack.MaxSearchWeight)
+		}
+	}
+
+	sr.plates = rack.AssumeDefaults()
+	sr.plates.BarWeight = sr.BarWeight
+	if sr.Inventory != "" {
+		inv, err := lookupInventory(r.Context(), sr.Inventory)
+		if err != nil {
+			return errors.New("inventory not found")
+		}
+		inv.Apply(&sr.plates)
+	}
+	if sr.plates.BarWeight == 0 {
+		sr.plates.BarWeight = rack.AssumeDefaults().BarWeight
+	}
+	if sr.plates.BarWeight < 0 {
+		return errors.New("bar weight cannot be negative")
+	}
+	return nil
+}
+
+// RackEmSessionPDF godoc
+// @Summary      Printable session sheet
+// @Description  Renders a planned session as a PDF with target weights, per-side plates and bar diagrams
+// @Tags         Rack
+// @Accept       json
+// @Produce      application/pdf
+// @Param        request  body      SessionSheetRequest  true  "Planned session"
+// @Success      200      {file}    file
+// @Failure      400      {object}  ErrResponse
+// @Failure      429      {object}  ErrResponse
+// @Failure      500      {object}  ErrResponse
+// @Router       /sessions/pdf [post]
+func RackEmSessionPDF(w http.ResponseWriter, r *http.Request) {
+	input := &SessionSheetRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	// Limit took one token for the request; each set after the first costs another
+	if len(input.Sets) > 1 && !rateLimiter.Charge(w, r, len(input.Sets)-1) {
+		return
+	}
+
+	loadings := make([]*rack.ReturnedValueStandard, len(input.Sets))
+	for i, set := range input.Sets {
+		if set.Weight <= input.plates.BarWeight {
+			continue
+		}
+		plates := input.plates
+		plates.DesiredWeight = set.Weight
+		loading, _, err := calculateCached(r.Context(), generateCacheKey(&plates), &plates)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Calculating weight for session sheet failed", "err", err, "input", plates)
+			render.Render(w, r, ErrInternal())
+			return
+		}
+		loadings[i] = loading
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="session-%s.pdf"`, input.date.Format(dateLayout)))
+	if err := WriteSessionPDF(w, input, loadings); err != nil {
+		slog.ErrorContext(r.Context(), "Writing session PDF failed", "err", err)
+	}
+}
+
+// WriteSessionPDF renders a planned session sheet. loadings holds the
+// calculated loading for each planned set, or nil for an empty bar.
+func WriteSessionPDF(w io.Writer, session *SessionSheetRequest, loadings []*rack.ReturnedValueStandard) error {
+	pdf, tr := newPDF(session.Title)
+
+	subtitle := fmt.Sprintf("%s  |  %d lb bar", session.date.Format("Monday, January 2, 2006"), session.plates.BarWeight)
+	if session.Athlete != "" {
+		subtitle = session.Athlete + "  |  " + subtitle
+	}
+	pdfHeading(pdf, tr, session.Title, subtitle)
+	if session.Notes != "" {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.MultiCell(0, 5, tr(session.Notes), "", "L", false)
+		pdf.Ln(3)
+	}
+
+	columns := []pdfColumn{
+		{"Exercise", 44, "L"},
+		{"Sets x Reps", 22, "C"},
+		{"Target", 16, "R"},
+		{"Per side", 52, "L"},
+		{"Bar", pdfDiagramWidth + 4, "L"},
+	}
+	pdfTableHeader(pdf, tr, columns)
+
+	for i, set := range session.Sets {
+		perSide := []float32{}
+		target := strconv.Itoa(set.Weight)
+		if loading := loadings[i]; loading != nil {
+			perSide = loading.LoadingOrder()
+			if loading.AchievedWeight != set.Weight {
+				target = fmt.Sprintf("%d*", loading.AchievedWeight)
+			}
+		}
+
+		exercise := set.Exercise
+		if set.Notes != "" {
+			exercise += " - " + set.Notes
+		}
+		cells := []string{
+			exercise,
+			fmt.Sprintf("%d x %d", set.Sets, set.Reps),
+			target,
+			perSideLabel(perSide, set.Weight, session.plates.BarWeight),
+		}
+		pdfTableRow(pdf, tr, columns, cells, perSide, false)
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 4, tr("Plates are listed per side in loading order. * = closest weight the available plates allow."), "", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// WriteChartPDF renders a loading chart, flagging weights that can't be loaded.
+func WriteChartPDF(w io.Writer, chart *ChartResponse) error {
+	title := fmt.Sprintf("Loading chart %d-%d lb", chart.From, chart.To)
+	pdf, tr := newPDF(title)
+	pdfHeading(pdf, tr, title, fmt.Sprintf("%d lb bar  |  %d lb steps  |  %s strategy  |  plates listed per side, in loading order", chart.BarWeight, chart.Step, chart.Strategy))
+
+	columns := []pdfColumn{
+		{"Weight", 22, "R"},
+		{"Per side", 110, "L"},
+		{"Bar", pdfDiagramWidth + 4, "L"},
+	}
+	pdfTableHeader(pdf, tr, columns)
+
+	for _, row := range chart.Rows {
+		label := perSideLabel(row.PerSide, row.Weight, chart.BarWeight)
+		if !row.Loadable {
+			label = "Can't load"
+			if row.AchievedWeight > 0 {
+				label = fmt.Sprintf("Can't load - closest is %d: %s", row.AchievedWeight, formatPlates(row.PerSide, "  "))
+			}
+		}
+		pdfTableRow(pdf, tr, columns, []string{strconv.Itoa(row.Weight), label}, row.PerSide, !row.Loadable)
+	}
+
+	return pdf.Output(w)
+}
+
+// newPDF creates a letter-sized document with page numbers in the footer.
+func newPDF(title string) (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("gorack", true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	return pdf, pdf.UnicodeTranslatorFromDescriptor("")
+}
+
+// pdfHeading writes the document title and a one-line subtitle.
+func pdfHeading(pdf *fpdf.Fpdf, tr func(string) string, title, subtitle string) {
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 8, tr(title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 6, tr(subtitle), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+}
+
+// pdfTableHeader writes the column titles of a table.
+func pdfTableHeader(pdf *fpdf.Fpdf, tr func(string) string, columns []pdfColumn) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetDrawColor(200, 200, 200)
+	for _, column := range columns {
+		pdf.CellFormat(column.width, 7, tr(column.title), "1", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// pdfTableRow writes one table row. The last column holds a bar diagram
+// drawn from perSide rather than text. The header is repeated after a page break.
+func pdfTableRow(pdf *fpdf.Fpdf, tr func(string) string, columns []pdfColumn, cells []string, perSide []float32, flagged bool) {
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+pdfRowHeight > pageHeight-bottom {
+		pdf.AddPage()
+		pdfTableHeader(pdf, tr, columns)
+	}
+
+	pdf.SetFont("Helvetica", "", 9)
+	if flagged {
+		pdf.SetFillColor(255, 238, 238)
+		pdf.SetTextColor(170, 0, 0)
+	} else {
+		pdf.SetTextColor(0, 0, 0)
+	}
+	for i, column := range columns[:len(columns)-1] {
+		pdf.CellFormat(column.width, pdfRowHeight, tr(cells[i]), "1", 0, column.align, flagged, 0, "")
+	}
+
+	diagram := columns[len(columns)-1]
+	x, y := pdf.GetXY()
+	pdf.CellFormat(diagram.width, pdfRowHeight, "", "1", 0, "L", flagged, 0, "")
+	drawBarDiagram(pdf, x+2, y+(pdfRowHeight-pdfDiagramHeight)/2, perSide)
+	pdf.Ln(-1)
+}
+
+// drawBarDiagram draws one side of the bar: the sleeve with plates stacked
+// from the collar outwards in loading order, sized by weight.
+func drawBarDiagram(pdf *fpdf.Fpdf, x, y float64, perSide []float32) {
+	mid := y + pdfDiagramHeight/2
+
+	// Shaft and collar, then the sleeve the plates slide onto
+	pdf.SetDrawColor(60, 60, 60)
+	pdf.SetFillColor(60, 60, 60)
+	pdf.SetLineWidth(0.6)
+	pdf.Line(x, mid, x+5, mid)
+	pdf.Rect(x+5, mid-1.5, 1.2, 3, "F")
+	pdf.SetLineWidth(1)
+	pdf.Line(x+6.2, mid, x+pdfDiagramWidth-2, mid)
+	pdf.SetLineWidth(0.2)
+
+	plateX := x + 6.6
+	for _, plate := range perSide {
+		width, shade := 2.2, 40
+		if plate < 25 {
+			width, shade = 1.4, 110
+		}
+		height := pdfDiagramHeight * (0.3 + 0.7*float64(plate)/100)
+		if plate >= 45 {
+			height = pdfDiagramHeight
+		}
+		if plateX+width > x+pdfDiagramWidth-2 {
+			break // More plates than fit in the cell
+		}
+		pdf.SetFillColor(shade, shade, shade)
+		pdf.Rect(plateX, mid-height/2, width, height, "F")
+		plateX += width + 0.4
+	}
+	pdf.SetDrawColor(200, 200, 200)
+}
+
+// perSideLabel describes the per-side plates for a table cell.
+func perSideLabel(perSide []float32, weight, barWeight int) string {
+	if len(perSide) > 0 {
+		return formatPlates(perSide, "  ")
+	}
+	if weight <= barWeight {
+		return "Empty bar"
+	}
+	return "-"
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isPDF reports whether a response is a PDF document.
+func isPDF(header http.Header, body []byte) bool {
+	return header.Get("Content-Type") == "application/pdf" && bytes.HasPrefix(body, []byte("%PDF-")) && len(body) > 1000
+}
+
+func TestRackEmSessionPDF(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+
+	rec := serveRequest(http.HandlerFunc(RackEmSessionPDF), "POST", "/v1/api/sessions/pdf", `{
+		"title": "Squat day", "athlete": "Sam", "date": "2026-10-20",
+		"sets": [
+			{"exercise": "Squat", "sets": 3, "reps": 5, "weight": 225},
+			{"exercise": "Squat", "reps": 10, "weight": 45, "notes": "warm-up"}
+		]
+	}`, "")
+	if rec.Code != http.StatusOK || !isPDF(rec.Header(), rec.Body.Bytes()) {
+		t.Fatalf("POST /sessions/pdf: %d, Content-Type %q, %d bytes", rec.Code, rec.Header().Get("Content-Type"), rec.Body.Len())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `inline; filename="session-2026-10-20.pdf"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	t.Setenv("BATCH_MAX_SIZE", "2")
+	set := `{"exercise": "Squat", "reps": 5, "weight": 225}`
+	tests := []struct {
+		name, body, error string
+	}{
+		{"no sets", `{"sets": []}`, "at least one set is required"},
+		{"too many sets", `{"sets": [` + strings.Repeat(set+",", 2) + set + `]}`, "a session sheet cannot have more than 2 sets"},
+		{"too heavy", `{"sets": [{"exercise": "Squat", "reps": 1, "weight": 20001}]}`, "set 1: weight cannot be more than 20000"},
+		{"no weight", `{"sets": [{"exercise": "Squat", "reps": 1}]}`, "set 1: weight must be a positive integer"},
+		{"date", `{"date": "2026-10-20T10:00:00Z", "sets": [` + set + `]}`, "date must be formatted as YYYY-MM-DD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(http.HandlerFunc(RackEmSessionPDF), "POST", "/v1/api/sessions/pdf", tt.body, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if got := decodeError(t, rec).ErrorText; got != tt.error {
+				t.Errorf("error = %q, want %q", got, tt.error)
+			}
+		})
+	}
+}
+
+func TestRackEmChartPDF(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "off", 0, 0)
+
+	rec := serveRequest(http.HandlerFunc(RackEmChart), "GET", "/v1/api/rack/chart?to=315&step=10&format=pdf", "", "")
+	if rec.Code != http.StatusOK || !isPDF(rec.Header(), rec.Body.Bytes()) {
+		t.Fatalf("GET /rack/chart as PDF: %d, Content-Type %q, %d bytes", rec.Code, rec.Header().Get("Content-Type"), rec.Body.Len())
+	}
+	if rec := serveRequest(http.HandlerFunc(RackEmChart), "GET", "/v1/api/rack/chart?from=300&to=200&format=pdf", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("backwards chart as PDF: %d, want 400", rec.Code)
+	}
+}
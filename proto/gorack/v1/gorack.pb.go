@@ -0,0 +1,1018 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: gorack/v1/gorack.proto
+
+package gorackv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Plates is a bar and a number of PAIRS of each plate.
+type Plates struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	BarWeight      int32                  `protobuf:"varint,1,opt,name=bar_weight,json=barWeight,proto3" json:"bar_weight,omitempty"`
+	Hundreds       int32                  `protobuf:"varint,2,opt,name=hundreds,proto3" json:"hundreds,omitempty"`
+	FortyFives     int32                  `protobuf:"varint,3,opt,name=forty_fives,json=fortyFives,proto3" json:"forty_fives,omitempty"`
+	ThirtyFives    int32                  `protobuf:"varint,4,opt,name=thirty_fives,json=thirtyFives,proto3" json:"thirty_fives,omitempty"`
+	TwentyFives    int32                  `protobuf:"varint,5,opt,name=twenty_fives,json=twentyFives,proto3" json:"twenty_fives,omitempty"`
+	Tens           int32                  `protobuf:"varint,6,opt,name=tens,proto3" json:"tens,omitempty"`
+	Fives          int32                  `protobuf:"varint,7,opt,name=fives,proto3" json:"fives,omitempty"`
+	TwoDotFives    int32                  `protobuf:"varint,8,opt,name=two_dot_fives,json=twoDotFives,proto3" json:"two_dot_fives,omitempty"`
+	OneDotTwoFives int32                  `protobuf:"varint,9,opt,name=one_dot_two_fives,json=oneDotTwoFives,proto3" json:"one_dot_two_fives,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Plates) Reset() {
+	*x = Plates{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plates) ProtoMessage() {}
+
+func (x *Plates) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plates.ProtoReflect.Descriptor instead.
+func (*Plates) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Plates) GetBarWeight() int32 {
+	if x != nil {
+		return x.BarWeight
+	}
+	return 0
+}
+
+func (x *Plates) GetHundreds() int32 {
+	if x != nil {
+		return x.Hundreds
+	}
+	return 0
+}
+
+func (x *Plates) GetFortyFives() int32 {
+	if x != nil {
+		return x.FortyFives
+	}
+	return 0
+}
+
+func (x *Plates) GetThirtyFives() int32 {
+	if x != nil {
+		return x.ThirtyFives
+	}
+	return 0
+}
+
+func (x *Plates) GetTwentyFives() int32 {
+	if x != nil {
+		return x.TwentyFives
+	}
+	return 0
+}
+
+func (x *Plates) GetTens() int32 {
+	if x != nil {
+		return x.Tens
+	}
+	return 0
+}
+
+func (x *Plates) GetFives() int32 {
+	if x != nil {
+		return x.Fives
+	}
+	return 0
+}
+
+func (x *Plates) GetTwoDotFives() int32 {
+	if x != nil {
+		return x.TwoDotFives
+	}
+	return 0
+}
+
+func (x *Plates) GetOneDotTwoFives() int32 {
+	if x != nil {
+		return x.OneDotTwoFives
+	}
+	return 0
+}
+
+type CalculateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DesiredWeight int32                  `protobuf:"varint,1,opt,name=desired_weight,json=desiredWeight,proto3" json:"desired_weight,omitempty"`
+	// Available plates. Leave unset to use the server's default plates.
+	Plates *Plates `protobuf:"bytes,2,opt,name=plates,proto3" json:"plates,omitempty"`
+	// Inventory profile ID, replaces the plates.
+	Inventory string `protobuf:"bytes,3,opt,name=inventory,proto3" json:"inventory,omitempty"`
+	// Solver strategy, defaults to greedy.
+	Strategy string `protobuf:"bytes,4,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	// Include the solver's decision trace.
+	Explain       bool `protobuf:"varint,5,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateRequest) Reset() {
+	*x = CalculateRequest{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateRequest) ProtoMessage() {}
+
+func (x *CalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateRequest.ProtoReflect.Descriptor instead.
+func (*CalculateRequest) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CalculateRequest) GetDesiredWeight() int32 {
+	if x != nil {
+		return x.DesiredWeight
+	}
+	return 0
+}
+
+func (x *CalculateRequest) GetPlates() *Plates {
+	if x != nil {
+		return x.Plates
+	}
+	return nil
+}
+
+func (x *CalculateRequest) GetInventory() string {
+	if x != nil {
+		return x.Inventory
+	}
+	return ""
+}
+
+func (x *CalculateRequest) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *CalculateRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+type CalculateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bar weight and the pairs of plates to load.
+	Plates         *Plates `protobuf:"bytes,1,opt,name=plates,proto3" json:"plates,omitempty"`
+	DesiredWeight  int32   `protobuf:"varint,2,opt,name=desired_weight,json=desiredWeight,proto3" json:"desired_weight,omitempty"`
+	AchievedWeight int32   `protobuf:"varint,3,opt,name=achieved_weight,json=achievedWeight,proto3" json:"achieved_weight,omitempty"`
+	Strategy       string  `protobuf:"bytes,4,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Message        string  `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	// Only set when explain was requested.
+	Explanation   *Explanation `protobuf:"bytes,6,opt,name=explanation,proto3" json:"explanation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateResponse) Reset() {
+	*x = CalculateResponse{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateResponse) ProtoMessage() {}
+
+func (x *CalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateResponse.ProtoReflect.Descriptor instead.
+func (*CalculateResponse) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CalculateResponse) GetPlates() *Plates {
+	if x != nil {
+		return x.Plates
+	}
+	return nil
+}
+
+func (x *CalculateResponse) GetDesiredWeight() int32 {
+	if x != nil {
+		return x.DesiredWeight
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetAchievedWeight() int32 {
+	if x != nil {
+		return x.AchievedWeight
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetExplanation() *Explanation {
+	if x != nil {
+		return x.Explanation
+	}
+	return nil
+}
+
+type Explanation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Steps         []*Step                `protobuf:"bytes,1,rep,name=steps,proto3" json:"steps,omitempty"`
+	Note          string                 `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Explanation) Reset() {
+	*x = Explanation{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Explanation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Explanation) ProtoMessage() {}
+
+func (x *Explanation) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Explanation.ProtoReflect.Descriptor instead.
+func (*Explanation) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Explanation) GetSteps() []*Step {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Explanation) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type Step struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plate         float32                `protobuf:"fixed32,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	Pairs         int32                  `protobuf:"varint,2,opt,name=pairs,proto3" json:"pairs,omitempty"`
+	Skipped       string                 `protobuf:"bytes,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Remaining     int32                  `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Step) Reset() {
+	*x = Step{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Step) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Step) ProtoMessage() {}
+
+func (x *Step) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Step.ProtoReflect.Descriptor instead.
+func (*Step) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Step) GetPlate() float32 {
+	if x != nil {
+		return x.Plate
+	}
+	return 0
+}
+
+func (x *Step) GetPairs() int32 {
+	if x != nil {
+		return x.Pairs
+	}
+	return 0
+}
+
+func (x *Step) GetSkipped() string {
+	if x != nil {
+		return x.Skipped
+	}
+	return ""
+}
+
+func (x *Step) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+type BatchCalculateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*CalculateRequest    `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCalculateRequest) Reset() {
+	*x = BatchCalculateRequest{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCalculateRequest) ProtoMessage() {}
+
+func (x *BatchCalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCalculateRequest.ProtoReflect.Descriptor instead.
+func (*BatchCalculateRequest) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchCalculateRequest) GetRequests() []*CalculateRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchCalculateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCalculateResponse) Reset() {
+	*x = BatchCalculateResponse{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCalculateResponse) ProtoMessage() {}
+
+func (x *BatchCalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCalculateResponse.ProtoReflect.Descriptor instead.
+func (*BatchCalculateResponse) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchCalculateResponse) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Exactly one of result and error is set.
+	Result        *CalculateResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error         *BatchError        `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchResult) GetResult() *CalculateResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchResult) GetError() *BatchError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type BatchError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// gRPC status code, e.g. InvalidArgument.
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchError) Reset() {
+	*x = BatchError{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchError) ProtoMessage() {}
+
+func (x *BatchError) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchError.ProtoReflect.Descriptor instead.
+func (*BatchError) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BatchError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ChartRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// First weight, defaults to the bar weight.
+	From int32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   int32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Increment between weights, defaults to 5.
+	Step int32 `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`
+	// Available plates. Leave unset to use the server's default plates.
+	Plates        *Plates `protobuf:"bytes,4,opt,name=plates,proto3" json:"plates,omitempty"`
+	Inventory     string  `protobuf:"bytes,5,opt,name=inventory,proto3" json:"inventory,omitempty"`
+	Strategy      string  `protobuf:"bytes,6,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChartRequest) Reset() {
+	*x = ChartRequest{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChartRequest) ProtoMessage() {}
+
+func (x *ChartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChartRequest.ProtoReflect.Descriptor instead.
+func (*ChartRequest) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ChartRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ChartRequest) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ChartRequest) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *ChartRequest) GetPlates() *Plates {
+	if x != nil {
+		return x.Plates
+	}
+	return nil
+}
+
+func (x *ChartRequest) GetInventory() string {
+	if x != nil {
+		return x.Inventory
+	}
+	return ""
+}
+
+func (x *ChartRequest) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+type ChartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BarWeight     int32                  `protobuf:"varint,1,opt,name=bar_weight,json=barWeight,proto3" json:"bar_weight,omitempty"`
+	From          int32                  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            int32                  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	Step          int32                  `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	Inventory     string                 `protobuf:"bytes,5,opt,name=inventory,proto3" json:"inventory,omitempty"`
+	Strategy      string                 `protobuf:"bytes,6,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Rows          []*ChartRow            `protobuf:"bytes,7,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChartResponse) Reset() {
+	*x = ChartResponse{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChartResponse) ProtoMessage() {}
+
+func (x *ChartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChartResponse.ProtoReflect.Descriptor instead.
+func (*ChartResponse) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ChartResponse) GetBarWeight() int32 {
+	if x != nil {
+		return x.BarWeight
+	}
+	return 0
+}
+
+func (x *ChartResponse) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ChartResponse) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ChartResponse) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *ChartResponse) GetInventory() string {
+	if x != nil {
+		return x.Inventory
+	}
+	return ""
+}
+
+func (x *ChartResponse) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *ChartResponse) GetRows() []*ChartRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type ChartRow struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Weight         int32                  `protobuf:"varint,1,opt,name=weight,proto3" json:"weight,omitempty"`
+	AchievedWeight int32                  `protobuf:"varint,2,opt,name=achieved_weight,json=achievedWeight,proto3" json:"achieved_weight,omitempty"`
+	Loadable       bool                   `protobuf:"varint,3,opt,name=loadable,proto3" json:"loadable,omitempty"`
+	// Plates on each side, in loading order.
+	PerSide       []float32 `protobuf:"fixed32,4,rep,packed,name=per_side,json=perSide,proto3" json:"per_side,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChartRow) Reset() {
+	*x = ChartRow{}
+	mi := &file_gorack_v1_gorack_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChartRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChartRow) ProtoMessage() {}
+
+func (x *ChartRow) ProtoReflect() protoreflect.Message {
+	mi := &file_gorack_v1_gorack_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChartRow.ProtoReflect.Descriptor instead.
+func (*ChartRow) Descriptor() ([]byte, []int) {
+	return file_gorack_v1_gorack_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ChartRow) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ChartRow) GetAchievedWeight() int32 {
+	if x != nil {
+		return x.AchievedWeight
+	}
+	return 0
+}
+
+func (x *ChartRow) GetLoadable() bool {
+	if x != nil {
+		return x.Loadable
+	}
+	return false
+}
+
+func (x *ChartRow) GetPerSide() []float32 {
+	if x != nil {
+		return x.PerSide
+	}
+	return nil
+}
+
+var File_gorack_v1_gorack_proto protoreflect.FileDescriptor
+
+const file_gorack_v1_gorack_proto_rawDesc = "" +
+	"\n" +
+	"\x16gorack/v1/gorack.proto\x12\tgorack.v1\"\xa3\x02\n" +
+	"\x06Plates\x12\x1d\n" +
+	"\n" +
+	"bar_weight\x18\x01 \x01(\x05R\tbarWeight\x12\x1a\n" +
+	"\bhundreds\x18\x02 \x01(\x05R\bhundreds\x12\x1f\n" +
+	"\vforty_fives\x18\x03 \x01(\x05R\n" +
+	"fortyFives\x12!\n" +
+	"\fthirty_fives\x18\x04 \x01(\x05R\vthirtyFives\x12!\n" +
+	"\ftwenty_fives\x18\x05 \x01(\x05R\vtwentyFives\x12\x12\n" +
+	"\x04tens\x18\x06 \x01(\x05R\x04tens\x12\x14\n" +
+	"\x05fives\x18\a \x01(\x05R\x05fives\x12\"\n" +
+	"\rtwo_dot_fives\x18\b \x01(\x05R\vtwoDotFives\x12)\n" +
+	"\x11one_dot_two_fives\x18\t \x01(\x05R\x0eoneDotTwoFives\"\xb8\x01\n" +
+	"\x10CalculateRequest\x12%\n" +
+	"\x0edesired_weight\x18\x01 \x01(\x05R\rdesiredWeight\x12)\n" +
+	"\x06plates\x18\x02 \x01(\v2\x11.gorack.v1.PlatesR\x06plates\x12\x1c\n" +
+	"\tinventory\x18\x03 \x01(\tR\tinventory\x12\x1a\n" +
+	"\bstrategy\x18\x04 \x01(\tR\bstrategy\x12\x18\n" +
+	"\aexplain\x18\x05 \x01(\bR\aexplain\"\xfe\x01\n" +
+	"\x11CalculateResponse\x12)\n" +
+	"\x06plates\x18\x01 \x01(\v2\x11.gorack.v1.PlatesR\x06plates\x12%\n" +
+	"\x0edesired_weight\x18\x02 \x01(\x05R\rdesiredWeight\x12'\n" +
+	"\x0fachieved_weight\x18\x03 \x01(\x05R\x0eachievedWeight\x12\x1a\n" +
+	"\bstrategy\x18\x04 \x01(\tR\bstrategy\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x128\n" +
+	"\vexplanation\x18\x06 \x01(\v2\x16.gorack.v1.ExplanationR\vexplanation\"H\n" +
+	"\vExplanation\x12%\n" +
+	"\x05steps\x18\x01 \x03(\v2\x0f.gorack.v1.StepR\x05steps\x12\x12\n" +
+	"\x04note\x18\x02 \x01(\tR\x04note\"j\n" +
+	"\x04Step\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\x02R\x05plate\x12\x14\n" +
+	"\x05pairs\x18\x02 \x01(\x05R\x05pairs\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\tR\askipped\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x05R\tremaining\"P\n" +
+	"\x15BatchCalculateRequest\x127\n" +
+	"\brequests\x18\x01 \x03(\v2\x1b.gorack.v1.CalculateRequestR\brequests\"J\n" +
+	"\x16BatchCalculateResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.gorack.v1.BatchResultR\aresults\"\x86\x01\n" +
+	"\vBatchResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x124\n" +
+	"\x06result\x18\x02 \x01(\v2\x1c.gorack.v1.CalculateResponseR\x06result\x12+\n" +
+	"\x05error\x18\x03 \x01(\v2\x15.gorack.v1.BatchErrorR\x05error\":\n" +
+	"\n" +
+	"BatchError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xab\x01\n" +
+	"\fChartRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x05R\x02to\x12\x12\n" +
+	"\x04step\x18\x03 \x01(\x05R\x04step\x12)\n" +
+	"\x06plates\x18\x04 \x01(\v2\x11.gorack.v1.PlatesR\x06plates\x12\x1c\n" +
+	"\tinventory\x18\x05 \x01(\tR\tinventory\x12\x1a\n" +
+	"\bstrategy\x18\x06 \x01(\tR\bstrategy\"\xc9\x01\n" +
+	"\rChartResponse\x12\x1d\n" +
+	"\n" +
+	"bar_weight\x18\x01 \x01(\x05R\tbarWeight\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x05R\x02to\x12\x12\n" +
+	"\x04step\x18\x04 \x01(\x05R\x04step\x12\x1c\n" +
+	"\tinventory\x18\x05 \x01(\tR\tinventory\x12\x1a\n" +
+	"\bstrategy\x18\x06 \x01(\tR\bstrategy\x12'\n" +
+	"\x04rows\x18\a \x03(\v2\x13.gorack.v1.ChartRowR\x04rows\"\x82\x01\n" +
+	"\bChartRow\x12\x16\n" +
+	"\x06weight\x18\x01 \x01(\x05R\x06weight\x12'\n" +
+	"\x0fachieved_weight\x18\x02 \x01(\x05R\x0eachievedWeight\x12\x1a\n" +
+	"\bloadable\x18\x03 \x01(\bR\bloadable\x12\x19\n" +
+	"\bper_side\x18\x04 \x03(\x02R\aperSide2\xe8\x01\n" +
+	"\vRackService\x12F\n" +
+	"\tCalculate\x12\x1b.gorack.v1.CalculateRequest\x1a\x1c.gorack.v1.CalculateResponse\x12U\n" +
+	"\x0eBatchCalculate\x12 .gorack.v1.BatchCalculateRequest\x1a!.gorack.v1.BatchCalculateResponse\x12:\n" +
+	"\x05Chart\x12\x17.gorack.v1.ChartRequest\x1a\x18.gorack.v1.ChartResponseB3Z1github.com/pachev/gorack/proto/gorack/v1;gorackv1b\x06proto3"
+
+var (
+	file_gorack_v1_gorack_proto_rawDescOnce sync.Once
+	file_gorack_v1_gorack_proto_rawDescData []byte
+)
+
+func file_gorack_v1_gorack_proto_rawDescGZIP() []byte {
+	file_gorack_v1_gorack_proto_rawDescOnce.Do(func() {
+		file_gorack_v1_gorack_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gorack_v1_gorack_proto_rawDesc), len(file_gorack_v1_gorack_proto_rawDesc)))
+	})
+	return file_gorack_v1_gorack_proto_rawDescData
+}
+
+var file_gorack_v1_gorack_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_gorack_v1_gorack_proto_goTypes = []any{
+	(*Plates)(nil),                 // 0: gorack.v1.Plates
+	(*CalculateRequest)(nil),       // 1: gorack.v1.CalculateRequest
+	(*CalculateResponse)(nil),      // 2: gorack.v1.CalculateResponse
+	(*Explanation)(nil),            // 3: gorack.v1.Explanation
+	(*Step)(nil),                   // 4: gorack.v1.Step
+	(*BatchCalculateRequest)(nil),  // 5: gorack.v1.BatchCalculateRequest
+	(*BatchCalculateResponse)(nil), // 6: gorack.v1.BatchCalculateResponse
+	(*BatchResult)(nil),            // 7: gorack.v1.BatchResult
+	(*BatchError)(nil),             // 8: gorack.v1.BatchError
+	(*ChartRequest)(nil),           // 9: gorack.v1.ChartRequest
+	(*ChartResponse)(nil),          // 10: gorack.v1.ChartResponse
+	(*ChartRow)(nil),               // 11: gorack.v1.ChartRow
+}
+var file_gorack_v1_gorack_proto_depIdxs = []int32{
+	0,  // 0: gorack.v1.CalculateRequest.plates:type_name -> gorack.v1.Plates
+	0,  // 1: gorack.v1.CalculateResponse.plates:type_name -> gorack.v1.Plates
+	3,  // 2: gorack.v1.CalculateResponse.explanation:type_name -> gorack.v1.Explanation
+	4,  // 3: gorack.v1.Explanation.steps:type_name -> gorack.v1.Step
+	1,  // 4: gorack.v1.BatchCalculateRequest.requests:type_name -> gorack.v1.CalculateRequest
+	7,  // 5: gorack.v1.BatchCalculateResponse.results:type_name -> gorack.v1.BatchResult
+	2,  // 6: gorack.v1.BatchResult.result:type_name -> gorack.v1.CalculateResponse
+	8,  // 7: gorack.v1.BatchResult.error:type_name -> gorack.v1.BatchError
+	0,  // 8: gorack.v1.ChartRequest.plates:type_name -> gorack.v1.Plates
+	11, // 9: gorack.v1.ChartResponse.rows:type_name -> gorack.v1.ChartRow
+	1,  // 10: gorack.v1.RackService.Calculate:input_type -> gorack.v1.CalculateRequest
+	5,  // 11: gorack.v1.RackService.BatchCalculate:input_type -> gorack.v1.BatchCalculateRequest
+	9,  // 12: gorack.v1.RackService.Chart:input_type -> gorack.v1.ChartRequest
+	2,  // 13: gorack.v1.RackService.Calculate:output_type -> gorack.v1.CalculateResponse
+	6,  // 14: gorack.v1.RackService.BatchCalculate:output_type -> gorack.v1.BatchCalculateResponse
+	10, // 15: gorack.v1.RackService.Chart:output_type -> gorack.v1.ChartResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_gorack_v1_gorack_proto_init() }
+func file_gorack_v1_gorack_proto_init() {
+	if File_gorack_v1_gorack_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gorack_v1_gorack_proto_rawDesc), len(file_gorack_v1_gorack_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gorack_v1_gorack_proto_goTypes,
+		DependencyIndexes: file_gorack_v1_gorack_proto_depIdxs,
+		MessageInfos:      file_gorack_v1_gorack_proto_msgTypes,
+	}.Build()
+	File_gorack_v1_gorack_proto = out.File
+	file_gorack_v1_gorack_proto_goTypes = nil
+	file_gorack_v1_gorack_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gorack/v1/gorack.proto
+
+package gorackv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RackService_Calculate_FullMethodName      = "/gorack.v1.RackService/Calculate"
+	RackService_BatchCalculate_FullMethodName = "/gorack.v1.RackService/BatchCalculate"
+	RackService_Chart_FullMethodName          = "/gorack.v1.RackService/Chart"
+)
+
+// RackServiceClient is the client API for RackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RackService is the gRPC counterpart of the /v1/api/rack endpoints. It uses
+// the same solvers and result cache, so both transports give the same answers.
+type RackServiceClient interface {
+	// Calculate mirrors GET /rack (no plates) and POST /rack (with plates).
+	Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error)
+	// BatchCalculate mirrors POST /rack/batch: invalid items get an error
+	// instead of failing the whole batch.
+	BatchCalculate(ctx context.Context, in *BatchCalculateRequest, opts ...grpc.CallOption) (*BatchCalculateResponse, error)
+	// Chart mirrors GET /rack/chart.
+	Chart(ctx context.Context, in *ChartRequest, opts ...grpc.CallOption) (*ChartResponse, error)
+}
+
+type rackServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRackServiceClient(cc grpc.ClientConnInterface) RackServiceClient {
+	return &rackServiceClient{cc}
+}
+
+func (c *rackServiceClient) Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculateResponse)
+	err := c.cc.Invoke(ctx, RackService_Calculate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rackServiceClient) BatchCalculate(ctx context.Context, in *BatchCalculateRequest, opts ...grpc.CallOption) (*BatchCalculateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCalculateResponse)
+	err := c.cc.Invoke(ctx, RackService_BatchCalculate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rackServiceClient) Chart(ctx context.Context, in *ChartRequest, opts ...grpc.CallOption) (*ChartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChartResponse)
+	err := c.cc.Invoke(ctx, RackService_Chart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RackServiceServer is the server API for RackService service.
+// All implementations must embed UnimplementedRackServiceServer
+// for forward compatibility.
+//
+// RackService is the gRPC counterpart of the /v1/api/rack endpoints. It uses
+// the same solvers and result cache, so both transports give the same answers.
+type RackServiceServer interface {
+	// Calculate mirrors GET /rack (no plates) and POST /rack (with plates).
+	Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error)
+	// BatchCalculate mirrors POST /rack/batch: invalid items get an error
+	// instead of failing the whole batch.
+	BatchCalculate(context.Context, *BatchCalculateRequest) (*BatchCalculateResponse, error)
+	// Chart mirrors GET /rack/chart.
+	Chart(context.Context, *ChartRequest) (*ChartResponse, error)
+	mustEmbedUnimplementedRackServiceServer()
+}
+
+// UnimplementedRackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRackServiceServer struct{}
+
+func (UnimplementedRackServiceServer) Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Calculate not implemented")
+}
+func (UnimplementedRackServiceServer) BatchCalculate(context.Context, *BatchCalculateRequest) (*BatchCalculateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCalculate not implemented")
+}
+func (UnimplementedRackServiceServer) Chart(context.Context, *ChartRequest) (*ChartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chart not implemented")
+}
+func (UnimplementedRackServiceServer) mustEmbedUnimplementedRackServiceServer() {}
+func (UnimplementedRackServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeRackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RackServiceServer will
+// result in compilation errors.
+type UnsafeRackServiceServer interface {
+	mustEmbedUnimplementedRackServiceServer()
+}
+
+func RegisterRackServiceServer(s grpc.ServiceRegistrar, srv RackServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RackService_ServiceDesc, srv)
+}
+
+func _RackService_Calculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RackServiceServer).Calculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RackService_Calculate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RackServiceServer).Calculate(ctx, req.(*CalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RackService_BatchCalculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RackServiceServer).BatchCalculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RackService_BatchCalculate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RackServiceServer).BatchCalculate(ctx, req.(*BatchCalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RackService_Chart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RackServiceServer).Chart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RackService_Chart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RackServiceServer).Chart(ctx, req.(*ChartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RackService_ServiceDesc is the grpc.ServiceDesc for RackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gorack.v1.RackService",
+	HandlerType: (*RackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Calculate",
+			Handler:    _RackService_Calculate_Handler,
+		},
+		{
+			MethodName: "BatchCalculate",
+			Handler:    _RackService_BatchCalculate_Handler,
+		},
+		{
+			MethodName: "Chart",
+			Handler:    _RackService_Chart_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gorack/v1/gorack.proto",
+}
@@ -0,0 +1,46 @@
+package rack
+
+import "testing"
+
+// homeGym is a small plate set like the ones POST /rack gets.
+func homeGym(desiredWeight int) *RackInputStandard {
+	return plates(45, desiredWeight, plateCounts{0, 2, 2, 1, 2, 2, 1, 1})
+}
+
+// The benchmarks time the solvers without the server's cache or lookup
+// table, the work behind every cache miss: GET /rack uses the default
+// plates, POST /rack a home-gym set. Run them before and after touching
+// this package with `go test -bench . -benchmem ./rack`.
+func BenchmarkSolvers(b *testing.B) {
+	benchmarks := []struct {
+		name     string
+		input    *RackInputStandard
+		strategy string
+		explain  bool
+	}{
+		{"get/greedy/315", defaults(315), "greedy", false},
+		{"get/greedy/1005", defaults(1005), "greedy", false},
+		{"get/exact/315", defaults(315), "exact", false},
+		{"get/fewest-plates/1005", defaults(1005), "fewest-plates", false},
+		{"get/greedy/315/explain", defaults(315), "greedy", true},
+		{"post/greedy/285", homeGym(285), "greedy", false},
+		{"post/exact/285", homeGym(285), "exact", false},
+		{"post/keep-big-free/285", homeGym(285), "keep-big-free", false},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			solve := CalculateWeight
+			if bm.explain {
+				solve = Explain
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				input := *bm.input
+				input.Strategy = bm.strategy
+				if _, err := solve(&input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
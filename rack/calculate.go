@@ -0,0 +1,177 @@
+package rack
+
+import "math"
+
+// plateSpec is one row of the plate table the solvers work from.
+type plateSpec struct {
+	name   string  // Field name in RackInputStandard, as listed in PlateOrder
+	weight float32 // Weight of a single plate
+	halves int     // Weight a pair adds to the bar, in half pounds
+}
+
+// numPlates is the number of plate types.
+const numPlates = 8
+
+// plateTable lists every plate type from heaviest to lightest.
+var plateTable = [numPlates]plateSpec{
+	{name: "Hundos", weight: 100},
+	{name: "FortyFives", weight: 45},
+	{name: "ThirtyFives", weight: 35},
+	{name: "TwentyFives", weight: 25},
+	{name: "Tens", weight: 10},
+	{name: "Fives", weight: 5},
+	{name: "TwoDotFives", weight: 2.5},
+	{name: "OneDotTwoFives", weight: 1.25},
+}
+
+func init() {
+	for i := range plateTable {
+		plateTable[i].halves = int(plateTable[i].weight * 4)
+	}
+}
+
+// plateCounts holds a number of pairs per plate, indexed like plateTable.
+type plateCounts [numPlates]int
+
+// counts returns the input's pairs of each plate.
+func (ris *RackInputStandard) counts() plateCounts {
+	return plateCounts{
+		ris.Hundos, ris.FortyFives, ris.ThirtyFives, ris.TwentyFives,
+		ris.Tens, ris.Fives, ris.TwoDotFives, ris.OneDotTwoFives,
+	}
+}
+
+// setCounts replaces the input's pairs of each plate.
+func (ris *RackInputStandard) setCounts(counts plateCounts) {
+	ris.Hundos, ris.FortyFives, ris.ThirtyFives, ris.TwentyFives = counts[0], counts[1], counts[2], counts[3]
+	ris.Tens, ris.Fives, ris.TwoDotFives, ris.OneDotTwoFives = counts[4], counts[5], counts[6], counts[7]
+}
+
+// WeightAmounts is a translation for keynames in amounts (weight per single plate)
+var WeightAmounts = func() map[string]float32 {
+	amounts := make(map[string]float32, numPlates)
+	for _, plate := range plateTable {
+		amounts[plate.name] = plate.weight
+	}
+	return amounts
+}()
+
+// PlateOrder is the order plates are tried and loaded, from heaviest to lightest.
+var PlateOrder = func() []string {
+	order := make([]string, numPlates)
+	for i, plate := range plateTable {
+		order[i] = plate.name
+	}
+	return order
+}()
+
+// CalculateWeight is the core logic for calculating plates needed.
+// Input represents available plates. Output represents plates to use.
+// The input's Strategy picks the Solver, see Register.
+func CalculateWeight(inputAvailablePlates *RackInputStandard) (*ReturnedValueStandard, error) {
+	name, solver, err := lookupStrategy(inputAvailablePlates.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	result, err := solver.Solve(inputAvailablePlates)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = name
+	return result, nil
+}
+
+// Explain is CalculateWeight with the solver's decision trace attached to the
+// result. Solvers that don't implement Explainer get a note saying so.
+func Explain(inputAvailablePlates *RackInputStandard) (*ReturnedValueStandard, error) {
+	name, solver, err := lookupStrategy(inputAvailablePlates.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	var result *ReturnedValueStandard
+	if explainer, ok := solver.(Explainer); ok {
+		result, err = explainer.Explain(inputAvailablePlates)
+	} else {
+		result, err = solver.Solve(inputAvailablePlates)
+		if err == nil {
+			result.Explanation = &Explanation{Steps: []Step{}, Note: "The " + name + " strategy doesn't record its decisions."}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = name
+	return result, nil
+}
+
+// lookupStrategy resolves a strategy name, defaulting an empty one.
+func lookupStrategy(name string) (string, Solver, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	solver, err := Lookup(name)
+	return name, solver, err
+}
+
+// greedySolver repeatedly loads the heaviest pair that still fits. It's fast
+// but can miss loadings that need lighter plates in place of a heavy one.
+type greedySolver struct{}
+
+func (greedySolver) Solve(input *RackInputStandard) (*ReturnedValueStandard, error) {
+	return solveGreedy(input, nil), nil
+}
+
+func (greedySolver) Explain(input *RackInputStandard) (*ReturnedValueStandard, error) {
+	trace := &Explanation{Steps: []Step{}}
+	result := solveGreedy(input, trace)
+	result.Explanation = trace
+	return result, nil
+}
+
+// solveGreedy runs the greedy loop, recording each decision in trace when
+// it isn't nil.
+func solveGreedy(inputAvailablePlates *RackInputStandard, trace *Explanation) *ReturnedValueStandard {
+	currentBarWeight := max(inputAvailablePlates.BarWeight, 0)                              // Ensure bar weight is not negative
+	leftOver := 2 * min(inputAvailablePlates.DesiredWeight-currentBarWeight, math.MaxInt/2) // In half pounds
+	achievedWeight := currentBarWeight
+
+	available := inputAvailablePlates.counts() // Deducted from as plates are loaded
+	var platesToUse plateCounts                // Pairs of each plate to load
+
+	for leftOver > 0 {
+		foundPlateInIteration := false
+		for i, plate := range plateTable {
+			// A pair of 1.25s adds 2.5 lb, so they go on two pairs at a time
+			// to keep the total in whole pounds
+			pairs := 1 + plate.halves%2
+			if available[i] < pairs {
+				trace.skip(i, SkipNoneLeft, leftOver/2)
+				continue
+			}
+			if plate.halves > 0 && pairs*plate.halves <= leftOver {
+				leftOver -= pairs * plate.halves
+				platesToUse[i] += pairs
+				achievedWeight += pairs * plate.halves / 2
+				available[i] -= pairs
+				trace.load(i, pairs, leftOver/2)
+				foundPlateInIteration = true
+				break // Greedily take the heaviest possible, then restart outer loop for next heaviest
+			}
+			trace.skip(i, SkipTooHeavy, leftOver/2)
+		}
+		if !foundPlateInIteration {
+			break // No suitable plate could be added in this pass
+		}
+	}
+
+	outputPlates := RackInputStandard{
+		BarWeight:     currentBarWeight,
+		DesiredWeight: inputAvailablePlates.DesiredWeight,
+	}
+	outputPlates.setCounts(platesToUse)
+	return &ReturnedValueStandard{
+		RackInputStandard: &outputPlates,
+		AchievedWeight:    achievedWeight,
+		Message:           "You got this!",
+	}
+}
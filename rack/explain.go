@@ -0,0 +1,108 @@
+package rack
+
+import (
+	"fmt"
+	"math"
+)
+
+// Reasons a plate was passed over, used in Step.Skipped.
+const (
+	SkipNoneLeft  = "none left"
+	SkipTooHeavy  = "too heavy"
+	SkipDone      = "target reached"
+	SkipNotChosen = "not in the best combination"
+)
+
+// Explainer is implemented by solvers that can report how they reached a result.
+type Explainer interface {
+	// Explain solves input and sets the result's Explanation.
+	Explain(input *RackInputStandard) (*ReturnedValueStandard, error)
+}
+
+// Explanation is a solver's decision trace.
+type Explanation struct {
+	Steps []Step `json:"steps" xml:"steps>step"`              // Plates considered, in order
+	Note  string `json:"note,omitempty" xml:"note,omitempty"` // Why this result beat the alternatives
+}
+
+// Step is a single decision: a plate was either loaded or skipped.
+type Step struct {
+	Plate     float32 `json:"plate" xml:"plate"`                         // Weight of a single plate
+	Pairs     int     `json:"pairs,omitempty" xml:"pairs,omitempty"`     // Pairs loaded, 0 when skipped
+	Skipped   string  `json:"skipped,omitempty" xml:"skipped,omitempty"` // Why the plate wasn't loaded
+	Remaining int     `json:"remaining" xml:"remaining"`                 // Weight still to load after this step
+}
+
+// load records loading pairs of the plate at index plate in PlateOrder.
+// It's a no-op on a nil trace.
+func (e *Explanation) load(plate, pairs, remaining int) {
+	if e != nil {
+		e.Steps = append(e.Steps, Step{Plate: plateTable[plate].weight, Pairs: pairs, Remaining: remaining})
+	}
+}
+
+// skip records passing over the plate at index plate in PlateOrder. It's a
+// no-op on a nil trace.
+func (e *Explanation) skip(plate int, reason string, remaining int) {
+	if e != nil {
+		e.Steps = append(e.Steps, Step{Plate: plateTable[plate].weight, Skipped: reason, Remaining: remaining})
+	}
+}
+
+// Explain solves input, then walks the plates heaviest first to show what the
+// chosen combination loads and compares it with what greedy would have done.
+func (s searchSolver) Explain(input *RackInputStandard) (*ReturnedValueStandard, error) {
+	result, err := s.Solve(input)
+	if err != nil {
+		return nil, err
+	}
+	greedy := solveGreedy(input, nil)
+
+	trace := &Explanation{Steps: []Step{}}
+	leftOver := 2 * min(input.DesiredWeight-result.BarWeight, math.MaxInt/2) // In half pounds
+	available, used := input.counts(), result.counts()
+	for i, plate := range plateTable {
+		switch {
+		case used[i] > 0:
+			leftOver -= used[i] * plate.halves
+			trace.load(i, used[i], leftOver/2)
+		case available[i] <= 0:
+			trace.skip(i, SkipNoneLeft, leftOver/2)
+		case leftOver <= 0:
+			trace.skip(i, SkipDone, leftOver/2)
+		case plate.halves > leftOver:
+			trace.skip(i, SkipTooHeavy, leftOver/2)
+		default:
+			trace.skip(i, SkipNotChosen, leftOver/2)
+		}
+	}
+	trace.Note = s.note(result, greedy)
+	result.Explanation = trace
+	return result, nil
+}
+
+// note explains how the search result compares with the greedy one.
+func (s searchSolver) note(result, greedy *ReturnedValueStandard) string {
+	if result.AchievedWeight > greedy.AchievedWeight {
+		return fmt.Sprintf("Greedy stops at %d lb because it always takes the heaviest plate that fits; trying every combination reaches %d lb.",
+			greedy.AchievedWeight, result.AchievedWeight)
+	}
+	if s.count(result) < s.count(greedy) {
+		return fmt.Sprintf("Same weight as greedy (%d lb), with %d %s per side instead of %d.",
+			result.AchievedWeight, s.count(result), s.counted, s.count(greedy))
+	}
+	return "Greedy finds the same loading, and no other combination beats it."
+}
+
+// count totals the penalty of the pairs in a result, or the pairs themselves
+// for a solver without one.
+func (s searchSolver) count(result *ReturnedValueStandard) int {
+	total := 0
+	for i, pairs := range result.counts() {
+		if s.penalty != nil {
+			pairs *= s.penalty(plateTable[i].weight)
+		}
+		total += pairs
+	}
+	return total
+}
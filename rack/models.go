@@ -0,0 +1,164 @@
+package rack
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// Validation errors returned by RackInputStandard.Validate.
+var (
+	ErrNegativeBarWeight     = errors.New("bar weight cannot be negative")
+	ErrMissingDesiredWeight  = errors.New("a valid desired weight must be provided")
+	ErrDesiredWeightTooLight = errors.New("desired weight must be greater than bar weight")
+	ErrNegativePlateCount    = errors.New("plate counts cannot be negative")
+)
+
+// RackInputStandard defines the structure for API input (available plates)
+// and also for the plates to be used in the output.
+// Plate counts are number of PAIRS.
+type RackInputStandard struct {
+	BarWeight      int    `json:"barWeight,omitempty" xml:"barWeight,omitempty"`
+	Hundos         int    `json:"hundreds,omitempty" xml:"hundreds,omitempty"` // JSON tag "hundreds" for API compatibility
+	FortyFives     int    `json:"fortyFives,omitempty" xml:"fortyFives,omitempty"`
+	ThirtyFives    int    `json:"thirtyFives,omitempty" xml:"thirtyFives,omitempty"`
+	TwentyFives    int    `json:"twentyFives,omitempty" xml:"twentyFives,omitempty"`
+	Tens           int    `json:"tens,omitempty" xml:"tens,omitempty"`
+	Fives          int    `json:"fives,omitempty" xml:"fives,omitempty"`
+	TwoDotFives    int    `json:"twoDotFives,omitempty" xml:"twoDotFives,omitempty"`
+	OneDotTwoFives int    `json:"oneDotTwoFives,omitempty" xml:"oneDotTwoFives,omitempty"`
+	DesiredWeight  int    `json:"desiredWeight" xml:"desiredWeight"`           // Required in input
+	Strategy       string `json:"strategy,omitempty" xml:"strategy,omitempty"` // Solver strategy, defaults to DefaultStrategy
+}
+
+// Validate checks an input before calculation, defaulting a missing bar
+// weight to the standard Olympic bar.
+func (ris *RackInputStandard) Validate() error {
+	if ris.BarWeight == 0 { // If not provided, default to standard Olympic bar
+		ris.BarWeight = AssumeDefaults().BarWeight
+	}
+	if ris.BarWeight < 0 {
+		return ErrNegativeBarWeight
+	}
+	if ris.DesiredWeight == 0 {
+		return ErrMissingDesiredWeight
+	}
+	if ris.DesiredWeight <= ris.BarWeight {
+		return ErrDesiredWeightTooLight
+	}
+	// Plate counts (Hundos, FortyFives, etc.) default to 0 if not set,
+	// meaning "0 pairs available".
+	for _, count := range ris.counts() {
+		if count < 0 {
+			return ErrNegativePlateCount
+		}
+	}
+	if _, err := Lookup(ris.Strategy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PlateCount returns the number of pairs of a specific plate type.
+func (ris *RackInputStandard) PlateCount(plateName string) int {
+	switch plateName {
+	case "Hundos":
+		return ris.Hundos
+	case "FortyFives":
+		return ris.FortyFives
+	case "ThirtyFives":
+		return ris.ThirtyFives
+	case "TwentyFives":
+		return ris.TwentyFives
+	case "Tens":
+		return ris.Tens
+	case "Fives":
+		return ris.Fives
+	case "TwoDotFives":
+		return ris.TwoDotFives
+	case "OneDotTwoFives":
+		return ris.OneDotTwoFives
+	}
+	return 0
+}
+
+// SetPlateCount sets the number of pairs of a specific plate type.
+func (ris *RackInputStandard) SetPlateCount(plateName string, count int) {
+	switch plateName {
+	case "Hundos":
+		ris.Hundos = count
+	case "FortyFives":
+		ris.FortyFives = count
+	case "ThirtyFives":
+		ris.ThirtyFives = count
+	case "TwentyFives":
+		ris.TwentyFives = count
+	case "Tens":
+		ris.Tens = count
+	case "Fives":
+		ris.Fives = count
+	case "TwoDotFives":
+		ris.TwoDotFives = count
+	case "OneDotTwoFives":
+		ris.OneDotTwoFives = count
+	}
+}
+
+// DecreaseWeight reduces the count of a specific plate type.
+// This is called on the *copy* of available plates during calculation.
+func (ris *RackInputStandard) DecreaseWeight(plateName string) {
+	switch plateName {
+	case "Hundos":
+		ris.Hundos--
+	case "FortyFives":
+		ris.FortyFives--
+	case "ThirtyFives":
+		ris.ThirtyFives--
+	case "TwentyFives":
+		ris.TwentyFives--
+	case "Tens":
+		ris.Tens--
+	case "Fives":
+		ris.Fives--
+	case "TwoDotFives":
+		ris.TwoDotFives--
+	case "OneDotTwoFives":
+		ris.OneDotTwoFives--
+	}
+}
+
+// ReturnedValueStandard is the structure of the JSON response.
+type ReturnedValueStandard struct {
+	XMLName            xml.Name     `json:"-" xml:"rack"`
+	*RackInputStandard              // Embeds the plates *to use* for the lift
+	AchievedWeight     int          `json:"achievedWeight" xml:"achievedWeight"`
+	Message            string       `json:"message,omitempty" xml:"message,omitempty"`
+	Explanation        *Explanation `json:"explanation,omitempty" xml:"explanation,omitempty"` // Only set by Explain
+}
+
+// LoadingOrder lists the plates to load on each side of the bar, in the
+// order they go on (heaviest first).
+func (rv *ReturnedValueStandard) LoadingOrder() []float32 {
+	perSide := []float32{}
+	for i, pairs := range rv.counts() {
+		for range pairs {
+			perSide = append(perSide, plateTable[i].weight)
+		}
+	}
+	return perSide
+}
+
+// AssumeDefaults provides a default set of available plates and bar weight.
+// Used when the caller doesn't specify their available equipment.
+func AssumeDefaults() RackInputStandard {
+	return RackInputStandard{
+		BarWeight:      45, // Standard Olympic bar weight in lbs
+		Hundos:         10,
+		FortyFives:     10,
+		ThirtyFives:    10,
+		TwentyFives:    10,
+		Tens:           10,
+		Fives:          10,
+		TwoDotFives:    10,
+		OneDotTwoFives: 10,
+	}
+}
@@ -0,0 +1,147 @@
+// Package rack calculates how to load a barbell: given a target weight, a bar
+// and the plates available, it works out which plate pairs to put on.
+//
+// Most callers only need Solve:
+//
+//	solution, err := rack.Solve(225, rack.OlympicBar, rack.DefaultInventory())
+//	// solution.PerSide == []rack.Plate{rack.FortyFive, rack.FortyFive}
+//
+// The wire types used by the gorack HTTP API (RackInputStandard and
+// ReturnedValueStandard) and CalculateWeight are exported as well.
+package rack
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownPlate is returned when an inventory lists a plate the solver doesn't support.
+var ErrUnknownPlate = errors.New("unknown plate")
+
+// Plate is a plate size. Weight is for a single plate, in pounds.
+type Plate struct {
+	Name   string  `json:"name"`
+	Weight float32 `json:"weight"`
+}
+
+// The supported plates.
+var (
+	Hundred        = Plate{Name: "Hundos", Weight: 100}
+	FortyFive      = Plate{Name: "FortyFives", Weight: 45}
+	ThirtyFive     = Plate{Name: "ThirtyFives", Weight: 35}
+	TwentyFive     = Plate{Name: "TwentyFives", Weight: 25}
+	Ten            = Plate{Name: "Tens", Weight: 10}
+	Five           = Plate{Name: "Fives", Weight: 5}
+	TwoAndAHalf    = Plate{Name: "TwoDotFives", Weight: 2.5}
+	OneAndAQuarter = Plate{Name: "OneDotTwoFives", Weight: 1.25}
+)
+
+// Plates lists every supported plate, heaviest first.
+var Plates = []Plate{Hundred, FortyFive, ThirtyFive, TwentyFive, Ten, Five, TwoAndAHalf, OneAndAQuarter}
+
+// Bar is a barbell. Weight is in pounds.
+type Bar struct {
+	Name   string `json:"name,omitempty"`
+	Weight int    `json:"weight"`
+}
+
+// OlympicBar is the standard 45 lb barbell.
+var OlympicBar = Bar{Name: "Olympic", Weight: 45}
+
+// Bars is a catalog of common bars, lightest first.
+var Bars = []Bar{
+	{Name: "Technique", Weight: 15},
+	{Name: "EZ curl", Weight: 25},
+	{Name: "Women's Olympic", Weight: 35},
+	OlympicBar,
+	{Name: "Trap", Weight: 55},
+	{Name: "Safety squat", Weight: 65},
+}
+
+// Inventory is the number of PAIRS available for each plate.
+type Inventory map[Plate]int
+
+// DefaultInventory returns the plates assumed when none are given: ten pairs of each.
+func DefaultInventory() Inventory {
+	return InventoryFrom(AssumeDefaults())
+}
+
+// InventoryFrom returns the plate pairs listed in an input.
+func InventoryFrom(input RackInputStandard) Inventory {
+	inv := Inventory{}
+	for _, plate := range Plates {
+		if count := input.PlateCount(plate.Name); count > 0 {
+			inv[plate] = count
+		}
+	}
+	return inv
+}
+
+// Input builds the calculator input for loading bar to target with this inventory.
+func (inv Inventory) Input(bar Bar, target int) (RackInputStandard, error) {
+	input := RackInputStandard{BarWeight: bar.Weight, DesiredWeight: target}
+	for plate, count := range inv {
+		if _, ok := WeightAmounts[plate.Name]; !ok || WeightAmounts[plate.Name] != plate.Weight {
+			return input, fmt.Errorf("%w: %s (%v lb)", ErrUnknownPlate, plate.Name, plate.Weight)
+		}
+		input.SetPlateCount(plate.Name, count)
+	}
+	return input, nil
+}
+
+// Solution is how to load a bar for a target weight.
+type Solution struct {
+	Target   int       `json:"target"`
+	Achieved int       `json:"achieved"`
+	Bar      Bar       `json:"bar"`
+	Pairs    Inventory `json:"-"`       // Plate pairs to load
+	PerSide  []Plate   `json:"perSide"` // Plates on each side, in loading order
+	Strategy string    `json:"strategy"`
+}
+
+// Exact reports whether the solution reaches the target weight exactly.
+func (s Solution) Exact() bool {
+	return s.Achieved == s.Target
+}
+
+// NewSolution converts a calculator result into a Solution.
+func NewSolution(bar Bar, result *ReturnedValueStandard) Solution {
+	solution := Solution{
+		Target:   result.DesiredWeight,
+		Achieved: result.AchievedWeight,
+		Bar:      Bar{Name: bar.Name, Weight: result.BarWeight},
+		Pairs:    InventoryFrom(*result.RackInputStandard),
+		PerSide:  []Plate{},
+		Strategy: result.Strategy,
+	}
+	for _, plate := range Plates {
+		for i := 0; i < result.PlateCount(plate.Name); i++ {
+			solution.PerSide = append(solution.PerSide, plate)
+		}
+	}
+	return solution
+}
+
+// Solve works out how to load bar with plates from inv to reach target using
+// the DefaultStrategy. When the target can't be reached exactly the solution
+// gets as close as it can without going over; check Exact.
+func Solve(target int, bar Bar, inv Inventory) (Solution, error) {
+	return SolveWith(DefaultStrategy, target, bar, inv)
+}
+
+// SolveWith is Solve with a named strategy, see Strategies.
+func SolveWith(strategy string, target int, bar Bar, inv Inventory) (Solution, error) {
+	input, err := inv.Input(bar, target)
+	if err != nil {
+		return Solution{}, err
+	}
+	input.Strategy = strategy
+	if err := input.Validate(); err != nil {
+		return Solution{}, err
+	}
+	result, err := CalculateWeight(&input)
+	if err != nil {
+		return Solution{}, err
+	}
+	return NewSolution(bar, result), nil
+}
@@ -0,0 +1,219 @@
+package rack
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultStrategy is the strategy used when an input doesn't name one.
+const DefaultStrategy = "greedy"
+
+// MaxSearchWeight caps how many pounds of plates the search strategies will
+// consider. The greedy strategy has no limit.
+const MaxSearchWeight = 20000
+
+var (
+	// ErrUnknownStrategy is returned for a strategy name that isn't registered.
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	// ErrSearchTooLarge is returned when a search strategy is asked to load
+	// more than MaxSearchWeight.
+	ErrSearchTooLarge = errors.New("too much weight for this strategy")
+)
+
+// Solver picks the plates to load. Plate counts in the input are the pairs
+// available; plate counts in the result are the pairs to use.
+type Solver interface {
+	Solve(input *RackInputStandard) (*ReturnedValueStandard, error)
+}
+
+// SolverFunc adapts an ordinary function to the Solver interface.
+type SolverFunc func(input *RackInputStandard) (*ReturnedValueStandard, error)
+
+// Solve calls f(input).
+func (f SolverFunc) Solve(input *RackInputStandard) (*ReturnedValueStandard, error) {
+	return f(input)
+}
+
+var (
+	solversMu sync.RWMutex
+	solvers   = map[string]Solver{}
+)
+
+func init() {
+	Register("greedy", greedySolver{})
+	Register("exact", searchSolver{counted: "plates"})
+	Register("fewest-plates", searchSolver{counted: "plates", penalty: func(float32) int { return 1 }})
+	Register("fewest-small-plates", searchSolver{counted: "small plates (under 10 lb)", penalty: func(weight float32) int {
+		if weight < 10 {
+			return 1
+		}
+		return 0
+	}})
+	Register("keep-big-free", searchSolver{counted: "big plates (45 lb and up)", penalty: func(weight float32) int {
+		if weight >= 45 {
+			return 1
+		}
+		return 0
+	}})
+}
+
+// Register makes a solver available by name. It panics if the name is
+// empty or already registered.
+func Register(name string, solver Solver) {
+	solversMu.Lock()
+	defer solversMu.Unlock()
+	if name == "" || solver == nil {
+		panic("rack: Register called with an empty name or nil solver")
+	}
+	if _, dup := solvers[name]; dup {
+		panic("rack: Register called twice for strategy " + name)
+	}
+	solvers[name] = solver
+}
+
+// Lookup returns the solver registered under name. An empty name returns
+// the DefaultStrategy solver.
+func Lookup(name string) (Solver, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	solversMu.RLock()
+	solver, ok := solvers[name]
+	solversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w '%s': must be one of %s", ErrUnknownStrategy, name, strings.Join(Strategies(), ", "))
+	}
+	return solver, nil
+}
+
+// Strategies lists the registered strategy names in alphabetical order.
+func Strategies() []string {
+	solversMu.RLock()
+	defer solversMu.RUnlock()
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// searchSolver tries every combination of the available plates and returns
+// the heaviest loading that doesn't go over the target. Ties are broken by
+// the lowest total penalty (summed per pair), then by using the heaviest
+// plates, the same order greedy loads them.
+type searchSolver struct {
+	penalty func(weight float32) int // Cost of one pair of a plate, nil for none
+	counted string                   // What the penalty counts, for explanations
+}
+
+// searchItem is a bundle of pairs of one plate. Counts are split into
+// bundles of 1, 2, 4, ... pairs so every count can be made from them.
+type searchItem struct {
+	plate   int // Index into PlateOrder
+	pairs   int
+	weight  int // In half pounds
+	penalty int // Summed over the bundle's pairs
+}
+
+// costSize is the length of a search cost: the penalty, then the negated
+// pair counts indexed like PlateOrder, compared in order.
+const costSize = numPlates + 1
+
+func (s searchSolver) Solve(input *RackInputStandard) (*ReturnedValueStandard, error) {
+	barWeight := max(input.BarWeight, 0)
+	// Weights in the search are in half pounds. Anything past MaxSearchWeight
+	// is refused anyway, so capping the target there keeps the sums below
+	// from overflowing with huge targets and counts.
+	leftOver := 2 * min(input.DesiredWeight-barWeight, MaxSearchWeight+1)
+
+	items := make([]searchItem, 0, 4*numPlates)
+	available := 0
+	for i, count := range input.counts() {
+		plate := plateTable[i]
+		if plate.halves <= 0 || count <= 0 || leftOver <= 0 {
+			continue
+		}
+		penalty := 0
+		if s.penalty != nil {
+			penalty = s.penalty(plate.weight)
+		}
+		count = min(count, leftOver/plate.halves)
+		available += count * plate.halves
+		for bundle := 1; count > 0; bundle *= 2 {
+			pairs := min(bundle, count)
+			items = append(items, searchItem{plate: i, pairs: pairs, weight: pairs * plate.halves, penalty: pairs * penalty})
+			count -= pairs
+		}
+	}
+
+	limit := max(min(leftOver, available), 0)
+	if limit > 2*MaxSearchWeight {
+		return nil, fmt.Errorf("%w: can't search more than %d lb of plates", ErrSearchTooLarge, MaxSearchWeight)
+	}
+
+	// costs[w*costSize:] is the cheapest loading weighing exactly w, if
+	// reached[w]. took[n*width+w] records that item n improved it, which is
+	// enough to walk the choices back without copying pair counts around.
+	width := limit + 1
+	costs := make([]int, width*costSize)
+	reached := make([]bool, width)
+	took := make([]bool, len(items)*width)
+	reached[0] = true
+	var cost [costSize]int
+	for n, item := range items {
+		for w := limit; w >= item.weight; w-- {
+			from := w - item.weight
+			if !reached[from] {
+				continue
+			}
+			copy(cost[:], costs[from*costSize:])
+			cost[0] += item.penalty
+			cost[item.plate+1] -= item.pairs
+			best := costs[w*costSize : (w+1)*costSize]
+			if reached[w] && !lessCost(cost[:], best) {
+				continue
+			}
+			copy(best, cost[:])
+			reached[w] = true
+			took[n*width+w] = true
+		}
+	}
+
+	// Only whole pounds count: an odd number of 1.25 pairs leaves half a pound over
+	loaded := limit
+	for !reached[loaded] || loaded%2 != 0 {
+		loaded--
+	}
+	var platesToUse plateCounts
+	for n, w := len(items)-1, loaded; n >= 0; n-- {
+		if took[n*width+w] {
+			platesToUse[items[n].plate] += items[n].pairs
+			w -= items[n].weight
+		}
+	}
+
+	outputPlates := RackInputStandard{
+		BarWeight:     barWeight,
+		DesiredWeight: input.DesiredWeight,
+	}
+	outputPlates.setCounts(platesToUse)
+	return &ReturnedValueStandard{
+		RackInputStandard: &outputPlates,
+		AchievedWeight:    barWeight + loaded/2,
+		Message:           "You got this!",
+	}, nil
+}
+
+// lessCost compares two costs element by element.
+func lessCost(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package rack
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+// plates builds an input with the given pairs of each plate, heaviest first
+// like plateTable.
+func plates(barWeight, desiredWeight int, counts plateCounts) *RackInputStandard {
+	input := &RackInputStandard{BarWeight: barWeight, DesiredWeight: desiredWeight}
+	input.setCounts(counts)
+	return input
+}
+
+// defaults is the default plates with a target weight.
+func defaults(desiredWeight int) *RackInputStandard {
+	input := AssumeDefaults()
+	input.DesiredWeight = desiredWeight
+	return &input
+}
+
+func TestStrategies(t *testing.T) {
+	//                             100 45 35 25 10  5 2.5 1.25
+	ninetyPerSide := plates(45, 145, plateCounts{0, 1, 0, 2, 0, 1, 0, 0})
+	stuckOnFortyFives := plates(45, 145, plateCounts{0, 1, 1, 2, 0, 0, 0, 0})
+
+	tests := []struct {
+		name     string
+		input    *RackInputStandard
+		strategy string
+		want     plateCounts
+		achieved int
+	}{
+		// Default plates: everything reaches 315, keep-big-free without 100s or 45s
+		{"default greedy", defaults(315), "greedy", plateCounts{1, 0, 1}, 315},
+		{"default exact", defaults(315), "exact", plateCounts{1, 0, 1}, 315},
+		{"default fewest-plates", defaults(315), "fewest-plates", plateCounts{1, 0, 1}, 315},
+		{"default fewest-small-plates", defaults(315), "fewest-small-plates", plateCounts{1, 0, 1}, 315},
+		{"default keep-big-free", defaults(315), "keep-big-free", plateCounts{0, 0, 3, 1, 0, 1}, 315},
+		{"default greedy fractional", defaults(50), "greedy", plateCounts{0, 0, 0, 0, 0, 0, 1}, 50},
+
+		// 50 a side: 45+5 or 25+25. Ties go to the heavier plates
+		{"tie greedy", ninetyPerSide, "greedy", plateCounts{0, 1, 0, 0, 0, 1}, 145},
+		{"tie exact", ninetyPerSide, "exact", plateCounts{0, 1, 0, 0, 0, 1}, 145},
+		{"tie fewest-plates", ninetyPerSide, "fewest-plates", plateCounts{0, 1, 0, 0, 0, 1}, 145},
+		{"tie fewest-small-plates", ninetyPerSide, "fewest-small-plates", plateCounts{0, 0, 0, 2}, 145},
+		{"tie keep-big-free", ninetyPerSide, "keep-big-free", plateCounts{0, 0, 0, 2}, 145},
+
+		// Greedy takes the 45 and strands 5 a side; the searches find 25+25
+		{"limited greedy", stuckOnFortyFives, "greedy", plateCounts{0, 1}, 135},
+		{"limited exact", stuckOnFortyFives, "exact", plateCounts{0, 0, 0, 2}, 145},
+		{"limited fewest-plates", stuckOnFortyFives, "fewest-plates", plateCounts{0, 0, 0, 2}, 145},
+		{"limited fewest-small-plates", stuckOnFortyFives, "fewest-small-plates", plateCounts{0, 0, 0, 2}, 145},
+		{"limited keep-big-free", stuckOnFortyFives, "keep-big-free", plateCounts{0, 0, 0, 2}, 145},
+
+		// Unreachable: the closest loading under the target
+		{"unreachable greedy", plates(45, 200, plateCounts{0, 2}), "greedy", plateCounts{0, 1}, 135},
+		{"unreachable exact", plates(45, 200, plateCounts{0, 2}), "exact", plateCounts{0, 1}, 135},
+		{"unreachable fewest-plates", plates(45, 200, plateCounts{0, 2}), "fewest-plates", plateCounts{0, 1}, 135},
+		{"unreachable fewest-small-plates", plates(45, 200, plateCounts{0, 2}), "fewest-small-plates", plateCounts{0, 1}, 135},
+		{"unreachable keep-big-free", plates(45, 200, plateCounts{0, 2}), "keep-big-free", plateCounts{0, 1}, 135},
+		{"nothing fits greedy", plates(45, 100, plateCounts{0, 2}), "greedy", plateCounts{}, 45},
+		{"nothing fits exact", plates(45, 100, plateCounts{0, 2}), "exact", plateCounts{}, 45},
+		{"no plates", plates(45, 100, plateCounts{}), "fewest-plates", plateCounts{}, 45},
+		{"odd pound", defaults(46), "exact", plateCounts{}, 45},
+
+		// A pair of 1.25s adds 2.5 lb, so they only go on two pairs at a time
+		{"quarters greedy", plates(45, 50, plateCounts{0, 0, 0, 0, 0, 0, 0, 3}), "greedy", plateCounts{0, 0, 0, 0, 0, 0, 0, 2}, 50},
+		{"quarters exact", plates(45, 50, plateCounts{0, 0, 0, 0, 0, 0, 0, 3}), "exact", plateCounts{0, 0, 0, 0, 0, 0, 0, 2}, 50},
+		{"quarters under greedy", plates(45, 49, plateCounts{0, 0, 0, 0, 0, 0, 0, 3}), "greedy", plateCounts{}, 45},
+		{"quarters under exact", plates(45, 49, plateCounts{0, 0, 0, 0, 0, 0, 0, 3}), "exact", plateCounts{}, 45},
+		{"one quarter greedy", plates(45, 48, plateCounts{0, 0, 0, 0, 0, 0, 1, 1}), "greedy", plateCounts{}, 45},
+		{"one quarter exact", plates(45, 48, plateCounts{0, 0, 0, 0, 0, 0, 1, 1}), "exact", plateCounts{}, 45},
+		{"quarters with 2.5s exact", plates(45, 56, plateCounts{0, 0, 0, 0, 0, 0, 1, 2}), "exact", plateCounts{0, 0, 0, 0, 0, 0, 1, 2}, 55},
+
+		// Searches are capped at MaxSearchWeight of plates; greedy isn't
+		{"at search limit", plates(45, 45+MaxSearchWeight, plateCounts{200}), "exact", plateCounts{100}, 45 + MaxSearchWeight},
+		{"over search limit greedy", plates(45, 50+MaxSearchWeight, plateCounts{200, 0, 0, 0, 0, 0, 0, 2}), "greedy", plateCounts{100, 0, 0, 0, 0, 0, 0, 2}, 50 + MaxSearchWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := *tt.input
+			input.Strategy = tt.strategy
+			result, err := CalculateWeight(&input)
+			if err != nil {
+				t.Fatalf("CalculateWeight: %v", err)
+			}
+			if got := result.counts(); got != tt.want {
+				t.Errorf("plates = %v, want %v", got, tt.want)
+			}
+			if result.AchievedWeight != tt.achieved {
+				t.Errorf("achieved = %d, want %d", result.AchievedWeight, tt.achieved)
+			}
+			if result.Strategy != tt.strategy {
+				t.Errorf("strategy = %q, want %q", result.Strategy, tt.strategy)
+			}
+		})
+	}
+}
+
+func TestSearchTooLarge(t *testing.T) {
+	input := plates(45, 50+MaxSearchWeight, plateCounts{200, 0, 0, 0, 0, 0, 0, 2})
+	for _, strategy := range []string{"exact", "fewest-plates", "fewest-small-plates", "keep-big-free"} {
+		t.Run(strategy, func(t *testing.T) {
+			input := *input
+			input.Strategy = strategy
+			if _, err := CalculateWeight(&input); !errors.Is(err, ErrSearchTooLarge) {
+				t.Errorf("CalculateWeight() error = %v, want ErrSearchTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestHugeCounts(t *testing.T) {
+	huge := plateCounts{math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt}
+	input := plates(45, math.MaxInt, huge)
+	input.Strategy = "exact"
+	if _, err := CalculateWeight(input); !errors.Is(err, ErrSearchTooLarge) {
+		t.Errorf("CalculateWeight() error = %v, want ErrSearchTooLarge", err)
+	}
+
+	input = plates(45, 45+MaxSearchWeight, huge)
+	input.Strategy = "exact"
+	result, err := CalculateWeight(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AchievedWeight != 45+MaxSearchWeight || result.Hundos != 100 {
+		t.Errorf("result = %d with %d hundreds, want %d with 100", result.AchievedWeight, result.Hundos, 45+MaxSearchWeight)
+	}
+
+	// Greedy takes everything there is, however far off the target
+	result, err = CalculateWeight(plates(45, math.MaxInt, plateCounts{1, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AchievedWeight != 335 {
+		t.Errorf("greedy achieved %d, want 335", result.AchievedWeight)
+	}
+}
+
+func TestUnknownStrategy(t *testing.T) {
+	input := defaults(135)
+	input.Strategy = "heaviest"
+	if _, err := CalculateWeight(input); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("CalculateWeight() error = %v, want ErrUnknownStrategy", err)
+	}
+}
+
+// TestSearchesNeverWorse checks every search strategy reaches at least the
+// greedy weight, and never goes over the target, across default plates.
+func TestSearchesNeverWorse(t *testing.T) {
+	for weight := 46; weight <= 700; weight++ {
+		greedy, err := CalculateWeight(defaults(weight))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, strategy := range []string{"exact", "fewest-plates", "fewest-small-plates", "keep-big-free"} {
+			input := defaults(weight)
+			input.Strategy = strategy
+			result, err := CalculateWeight(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.AchievedWeight < greedy.AchievedWeight || result.AchievedWeight > weight {
+				t.Errorf("%s at %d: achieved %d, greedy %d", strategy, weight, result.AchievedWeight, greedy.AchievedWeight)
+			}
+		}
+	}
+}
+
+// TestPlatesAddUp checks the plates in every result add up to its achieved
+// weight, with an odd number of 1.25 pairs to choose from.
+func TestPlatesAddUp(t *testing.T) {
+	for weight := 46; weight <= 120; weight++ {
+		for _, strategy := range Strategies() {
+			//                              100 45 35 25 10 5 2.5 1.25
+			input := plates(45, weight, plateCounts{0, 0, 0, 1, 1, 1, 1, 3})
+			input.Strategy = strategy
+			result, err := CalculateWeight(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded := float32(result.BarWeight)
+			for _, plate := range result.LoadingOrder() {
+				loaded += 2 * plate
+			}
+			if loaded != float32(result.AchievedWeight) || result.AchievedWeight > weight {
+				t.Errorf("%s at %d: plates add up to %v, achieved %d", strategy, weight, loaded, result.AchievedWeight)
+			}
+		}
+	}
+}
+
+func TestNegativePlateCount(t *testing.T) {
+	input := defaults(135)
+	input.Tens = -1
+	if err := input.Validate(); !errors.Is(err, ErrNegativePlateCount) {
+		t.Errorf("Validate() error = %v, want ErrNegativePlateCount", err)
+	}
+}
+
+// TestHundredsInResult checks the hundreds survive a JSON round trip: the
+// plates in every result add up to its achieved weight, and only
+// keep-big-free leaves the 100s off at 545.
+func TestHundredsInResult(t *testing.T) {
+	for _, strategy := range Strategies() {
+		t.Run(strategy, func(t *testing.T) {
+			input := defaults(545)
+			input.Strategy = strategy
+			result, err := CalculateWeight(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded ReturnedValueStandard
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.RackInputStandard == nil {
+				t.Fatalf("no plates in %s", body)
+			}
+			if strategy != "keep-big-free" && decoded.Hundos == 0 {
+				t.Errorf("no hundreds in %s", body)
+			}
+			loaded := float32(decoded.BarWeight)
+			for _, plate := range decoded.LoadingOrder() {
+				loaded += 2 * plate
+			}
+			if loaded != float32(decoded.AchievedWeight) {
+				t.Errorf("plates in %s add up to %v", body, loaded)
+			}
+		})
+	}
+}
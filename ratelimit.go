@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// defaultRateLimits protects the calculations that can fill the cache with
+// unique bodies, over REST, the web UI and gRPC, while leaving the rest of
+// the API generous. Batches and charts spend a token per calculation, so
+// their buckets hold one of the largest allowed.
+const defaultRateLimits = "POST /v1/api/rack=60/m, POST /v1/api/rack/batch=100/m, GET /v1/api/rack/chart=1000/10m, GET /ui=60/m, " +
+	"GRPC /gorack.v1.RackService/Calculate=60/m, GRPC /gorack.v1.RackService/BatchCalculate=100/m, GRPC /gorack.v1.RackService/Chart=1000/10m, *=300/m"
+
+// anyRoute is the RATE_LIMITS entry for routes without their own limit.
+const anyRoute = "*"
+
+// RateLimit allows Requests per Period, with bursts of up to Requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitConfig sets the limits a RateLimiter enforces.
+type RateLimitConfig struct {
+	Limits        map[string]RateLimit // By "METHOD /route/pattern", or "*" for every other route
+	DailyQuota    int                  // Requests per API key per UTC day, 0 for no quota
+	SweepInterval time.Duration        // How often idle clients are forgotten
+}
+
+// KeyUsage is an API key's requests so far today.
+type KeyUsage struct {
+	KeyID    string `json:"keyId"`
+	Date     string `json:"date"` // UTC, as YYYY-MM-DD
+	Requests int    `json:"requests"`
+	Quota    int    `json:"quota,omitempty"` // 0 when there's no daily quota
+}
+
+// RateLimiter is token-bucket rate limiting per client and route, where a
+// client is its API key or, without one, its IP address. It also counts
+// each key's requests per day and enforces the daily quota.
+type RateLimiter struct {
+	mu      sync.Mutex
+	config  RateLimitConfig
+	buckets map[string]*tokenBucket // By client and route
+	usage   map[string]*KeyUsage    // By API key ID
+	now     func() time.Time
+	stop    chan struct{}
+}
+
+// tokenBucket holds a client's tokens for one limit. A full bucket is the
+// same as no bucket, which is what lets sweep drop idle ones.
+type tokenBucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+// ParseRateLimits reads a RATE_LIMITS value: comma-separated
+// "METHOD /route/pattern=requests/period" entries, where the route is a chi
+// pattern such as /v1/api/inventories/{inventoryID}, or GRPC and a full
+// method name, and the period a duration or s, m, h or d.
+// "*=requests/period" covers every other route, and "off" disables rate
+// limiting.
+func ParseRateLimits(spec string) (map[string]RateLimit, error) {
+	limits := map[string]RateLimit{}
+	if strings.TrimSpace(spec) == "off" {
+		return limits, nil
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q: want route=requests/period", entry)
+		}
+		route = strings.Join(strings.Fields(route), " ")
+		if method, _, _ := strings.Cut(route, " "); route != anyRoute && (method != strings.ToUpper(method) || !strings.Contains(route, " /")) {
+			return nil, fmt.Errorf("rate limit %q: route must be * or METHOD /pattern", entry)
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit %q: %w", entry, err)
+		}
+		limits[route] = limit
+	}
+	return limits, nil
+}
+
+// parseRateLimit reads "requests/period", such as 60/m or 10/30s.
+func parseRateLimit(value string) (RateLimit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, errors.New("want requests/period")
+	}
+	limit := RateLimit{}
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return RateLimit{}, errors.New("requests must be a positive integer")
+	}
+	switch period {
+	case "s":
+		limit.Period = time.Second
+	case "m":
+		limit.Period = time.Minute
+	case "h":
+		limit.Period = time.Hour
+	case "d":
+		limit.Period = 24 * time.Hour
+	default:
+		if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+			return RateLimit{}, errors.New("period must be s, m, h, d or a positive duration")
+		}
+	}
+	return limit, nil
+}
+
+// NewRateLimiter creates a limiter, starting the sweeper when it has an
+// interval.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	rl := &RateLimiter{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+		usage:   make(map[string]*KeyUsage),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+	if config.SweepInterval > 0 {
+		go rl.sweep(config.SweepInterval)
+	}
+	return rl
+}
+
+// Limit is middleware that rejects requests over their route's limit or
+// their key's daily quota with 429 Too Many Requests. It must run after
+// Authenticate so requests with an API key are limited by key. Responses
+// carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset for the
+// limit closest to running out, and RateLimit-Policy for every limit that
+// applies.
+func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := rateLimitRoute(r)
+		decision := rl.check(route, clientIP(r), PrincipalFrom(r.Context()), 1, true)
+		decision.setHeaders(w.Header())
+		if decision.err != nil {
+			rateLimited.WithLabelValues(routePatternOrUnmatched(route), decision.reason).Inc()
+			render.Render(w, r, ErrTooManyRequests(decision.err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitRPC is Limit for gRPC, as a unary interceptor to chain after
+// authenticateRPC. Methods are limited as "GRPC /package.Service/Method"
+// routes, clients without a key by their peer address. The RateLimit
+// headers go back in the response metadata, and calls over a limit fail
+// with ResourceExhausted.
+func (rl *RateLimiter) LimitRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	route := "GRPC " + info.FullMethod
+	decision := rl.check(route, peerIP(ctx), PrincipalFrom(ctx), 1, true)
+	header := http.Header{}
+	decision.setHeaders(header)
+	if decision.err != nil {
+		setRPCHeader(ctx, header)
+		rateLimited.WithLabelValues(routePatternOrUnmatched(route), decision.reason).Inc()
+		return nil, status.Error(codes.ResourceExhausted, decision.err.Error())
+	}
+	// The headers go back once the call is done, so ChargeRPC can update them
+	resp, err := handler(context.WithValue(ctx, rpcHeaderKey{}, header), req)
+	setRPCHeader(ctx, header)
+	return resp, err
+}
+
+// rpcHeaderKey is the context key for the RateLimit headers of a gRPC call.
+type rpcHeaderKey struct{}
+
+// setRPCHeader sends RateLimit headers back as response metadata.
+func setRPCHeader(ctx context.Context, header http.Header) {
+	if len(header) == 0 {
+		return
+	}
+	md := metadata.MD{}
+	for name, values := range header {
+		md.Append(name, values...)
+	}
+	grpc.SetHeader(ctx, md)
+}
+
+// Charge spends tokens more from the bucket a request already took one from
+// in Limit, for requests that cost more than one once they've been read,
+// such as a GraphQL query running many calculations. They don't count
+// against the daily quota again. When the bucket doesn't have them, Charge
+// answers 429 Too Many Requests and returns false.
+func (rl *RateLimiter) Charge(w http.ResponseWriter, r *http.Request, tokens int) bool {
+	route := rateLimitRoute(r)
+	decision := rl.check(route, clientIP(r), PrincipalFrom(r.Context()), tokens, false)
+	decision.setHeaders(w.Header())
+	if decision.err != nil {
+		rateLimited.WithLabelValues(routePatternOrUnmatched(route), decision.reason).Inc()
+		render.Render(w, r, ErrTooManyRequests(decision.err))
+		return false
+	}
+	return true
+}
+
+// ChargeRPC is Charge for gRPC: it spends tokens more from the bucket a call
+// already took one from in LimitRPC, such as one per item in a batch, and
+// updates the headers sent back. When the bucket doesn't have them, the
+// returned error is ResourceExhausted.
+func (rl *RateLimiter) ChargeRPC(ctx context.Context, tokens int) error {
+	method, _ := grpc.Method(ctx)
+	route := "GRPC " + method
+	decision := rl.check(route, peerIP(ctx), PrincipalFrom(ctx), tokens, false)
+	if header, ok := ctx.Value(rpcHeaderKey{}).(http.Header); ok {
+		decision.setHeaders(header)
+	}
+	if decision.err != nil {
+		rateLimited.WithLabelValues(routePatternOrUnmatched(route), decision.reason).Inc()
+		return status.Error(codes.ResourceExhausted, decision.err.Error())
+	}
+	return nil
+}
+
+// rateDecision is whether a request may go ahead, and where its client
+// stands against the limits that apply.
+type rateDecision struct {
+	rate    rateStatus // The route's limit, when limited is set
+	quota   rateStatus // The key's daily quota, when it has one
+	limited bool       // Whether the route has a limit
+	reason  string     // "rate" or "quota" when the request is turned away
+	err     error      // Why the request is turned away, nil when it may go ahead
+}
+
+// check spends tokens from the client's bucket for route and, for a new
+// request, counts it against its key's daily quota. ip identifies clients
+// without a key; principal is nil for them.
+func (rl *RateLimiter) check(route, ip string, principal *Principal, tokens int, newRequest bool) rateDecision {
+	limitRoute := route
+	limit, ok := rl.config.Limits[route]
+	if !ok {
+		limitRoute = anyRoute
+		limit, ok = rl.config.Limits[anyRoute]
+	}
+
+	keyID := ""
+	if principal != nil {
+		keyID = principal.Key.ID
+	}
+	decision := rateDecision{limited: ok}
+	if !ok && keyID == "" {
+		return decision // Nothing to limit or count
+	}
+
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	var usage *KeyUsage
+	if keyID != "" {
+		usage = rl.keyUsage(keyID, now)
+	}
+	quota := rl.config.DailyQuota
+	if newRequest && usage != nil && quota > 0 && usage.Requests >= quota {
+		decision.reason = "quota"
+	}
+	if ok {
+		client := "ip:" + ip
+		if keyID != "" {
+			client = "key:" + keyID
+		}
+		// A request turned away by its quota doesn't spend a token
+		spend := tokens
+		if decision.reason != "" {
+			spend = 0
+		}
+		decision.rate = rl.take(client+" "+limitRoute, limit, now, spend)
+		if decision.reason == "" && !decision.rate.allowed {
+			decision.reason = "rate"
+		}
+	}
+	if newRequest && usage != nil && decision.reason == "" {
+		usage.Requests++
+	}
+	if usage != nil && quota > 0 {
+		decision.quota = rateStatus{
+			limit:     quota,
+			remaining: max(quota-usage.Requests, 0),
+			reset:     nextUTCDay(now).Sub(now),
+			retry:     nextUTCDay(now).Sub(now),
+			policy:    fmt.Sprintf("%d;w=86400", quota),
+		}
+	}
+
+	switch decision.reason {
+	case "quota":
+		decision.err = fmt.Errorf("daily quota of %d requests used up, it resets at midnight UTC", quota)
+	case "rate":
+		if tokens > limit.Requests {
+			decision.err = fmt.Errorf("request costs more than the rate limit of %d requests per %s allows", limit.Requests, limit.Period)
+		} else {
+			decision.err = fmt.Errorf("rate limit of %d requests per %s exceeded", limit.Requests, limit.Period)
+		}
+	}
+	return decision
+}
+
+// rateStatus is where a client stands against one limit.
+type rateStatus struct {
+	allowed   bool
+	limit     int
+	remaining int
+	reset     time.Duration // Until the limit is fully restored
+	retry     time.Duration // Until the next request is allowed
+	policy    string        // RateLimit-Policy item
+}
+
+// take spends tokens from the bucket for key, refilling it for the time
+// since it was last used. It spends all of them or, when the bucket is
+// short, none. The caller must hold rl.mu.
+func (rl *RateLimiter) take(key string, limit RateLimit, now time.Time, tokens int) rateStatus {
+	bucket, found := rl.buckets[key]
+	if !found || bucket.limit != limit {
+		bucket = &tokenBucket{limit: limit, tokens: float64(limit.Requests), updated: now}
+		rl.buckets[key] = bucket
+	}
+	bucket.refill(now)
+
+	status := rateStatus{
+		limit:  limit.Requests,
+		policy: fmt.Sprintf("%d;w=%d", limit.Requests, int(math.Ceil(limit.Period.Seconds()))),
+	}
+	if tokens > 0 && bucket.tokens >= float64(tokens) {
+		bucket.tokens -= float64(tokens)
+		status.allowed = true
+	}
+	status.remaining = int(bucket.tokens)
+	perToken := limit.Period / time.Duration(limit.Requests)
+	status.reset = time.Duration((float64(limit.Requests) - bucket.tokens) * float64(perToken))
+	// Until the next request could go ahead, or this one when it was turned away
+	needed := 1.0
+	if !status.allowed && tokens > 1 {
+		needed = float64(tokens)
+	}
+	if bucket.tokens < needed {
+		status.retry = time.Duration((needed - bucket.tokens) * float64(perToken))
+	}
+	return status
+}
+
+// refill adds the tokens earned since the bucket was last updated.
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	b.updated = now
+	if elapsed <= 0 {
+		return
+	}
+	earned := elapsed.Seconds() * float64(b.limit.Requests) / b.limit.Period.Seconds()
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+earned)
+}
+
+// keyUsage returns the key's usage for today, starting a new day when the
+// date has changed. The caller must hold rl.mu.
+func (rl *RateLimiter) keyUsage(keyID string, now time.Time) *KeyUsage {
+	today := now.UTC().Format(time.DateOnly)
+	usage, found := rl.usage[keyID]
+	if !found || usage.Date != today {
+		usage = &KeyUsage{KeyID: keyID, Date: today, Quota: rl.config.DailyQuota}
+		rl.usage[keyID] = usage
+	}
+	return usage
+}
+
+// Usage lists every key's requests today, busiest first.
+func (rl *RateLimiter) Usage() []KeyUsage {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	today := rl.now().UTC().Format(time.DateOnly)
+	usage := []KeyUsage{}
+	for _, u := range rl.usage {
+		if u.Date == today {
+			usage = append(usage, *u)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Requests != usage[j].Requests {
+			return usage[i].Requests > usage[j].Requests
+		}
+		return usage[i].KeyID < usage[j].KeyID
+	})
+	return usage
+}
+
+// Close stops the sweeper.
+func (rl *RateLimiter) Close() {
+	select {
+	case <-rl.stop:
+	default:
+		close(rl.stop)
+	}
+}
+
+// sweep forgets full buckets and past days' usage every interval until
+// Close is called, so one-off clients don't accumulate.
+func (rl *RateLimiter) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			rl.removeIdle(rl.now())
+		}
+	}
+}
+
+// removeIdle drops buckets that have refilled and usage from earlier days.
+func (rl *RateLimiter) removeIdle(now time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	for key, bucket := range rl.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Requests) {
+			delete(rl.buckets, key)
+		}
+	}
+	today := now.UTC().Format(time.DateOnly)
+	for keyID, usage := range rl.usage {
+		if usage.Date != today {
+			delete(rl.usage, keyID)
+		}
+	}
+}
+
+// setHeaders reports the limit closest to running out, and lists every
+// limit that applies in RateLimit-Policy. A request turned away also gets
+// Retry-After.
+func (d rateDecision) setHeaders(header http.Header) {
+	policies := []string{}
+	closest := d.rate
+	if d.limited {
+		policies = append(policies, d.rate.policy)
+	}
+	if d.quota.limit > 0 {
+		policies = append(policies, d.quota.policy)
+		if !d.limited || d.quota.remaining < d.rate.remaining {
+			closest = d.quota
+		}
+	}
+	if len(policies) == 0 {
+		return
+	}
+	header.Set("RateLimit-Limit", strconv.Itoa(closest.limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(closest.remaining))
+	header.Set("RateLimit-Reset", ceilSeconds(closest.reset))
+	header.Set("RateLimit-Policy", strings.Join(policies, ", "))
+	switch d.reason {
+	case "quota":
+		header.Set("Retry-After", ceilSeconds(d.quota.retry))
+	case "rate":
+		header.Set("Retry-After", ceilSeconds(d.rate.retry))
+	}
+}
+
+// rateLimitRoute is "METHOD /route/pattern" for the route r will be served
+// by, found ahead of routing so limits can be set per route. It's empty when
+// no route matches.
+func rateLimitRoute(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, path) {
+		return ""
+	}
+	return r.Method + " " + match.RoutePattern()
+}
+
+// routePatternOrUnmatched labels a rateLimitRoute result for metrics.
+func routePatternOrUnmatched(route string) string {
+	if _, pattern, ok := strings.Cut(route, " "); ok {
+		return pattern
+	}
+	return "unmatched"
+}
+
+// clientIP is the host part of the request's remote address. Set
+// TRUST_PROXY_HEADERS behind a proxy so it's the client's address instead
+// of the proxy's.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// peerIP is the host part of a gRPC caller's address.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// nextUTCDay is midnight UTC after now, when daily quotas reset.
+func nextUTCDay(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// ceilSeconds formats d as whole seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Global rate limiter for the API
+var rateLimiter *RateLimiter
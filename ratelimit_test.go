@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// useTestRateLimiter swaps the global rate limiter for one with limits, set
+// with RATE_LIMITS syntax, on a fake clock for the rest of the test. It
+// sweeps every sweepInterval when that isn't 0.
+func useTestRateLimiter(t *testing.T, limits string, dailyQuota int, sweepInterval time.Duration) (*RateLimiter, *fakeClock) {
+	t.Helper()
+	parsed, err := ParseRateLimits(limits)
+	if err != nil {
+		t.Fatalf("ParseRateLimits: %v", err)
+	}
+	clock := newFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	limiter := NewRateLimiter(RateLimitConfig{Limits: parsed, DailyQuota: dailyQuota})
+	limiter.now = clock.Now
+	if sweepInterval > 0 {
+		go limiter.sweep(sweepInterval)
+	}
+	previous := rateLimiter
+	rateLimiter = limiter
+	t.Cleanup(func() {
+		limiter.Close()
+		rateLimiter = previous
+	})
+	return limiter, clock
+}
+
+// limitedRouter serves 204 on GET /v1/api/rack and /v1/api/inventories
+// behind Authenticate and the global rate limiter, as the API does.
+func limitedRouter() http.Handler {
+	noContent := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	router := chi.NewRouter()
+	router.Route("/v1/api", func(r chi.Router) {
+		r.Use(Authenticate, rateLimiter.Limit)
+		r.Get("/rack", noContent)
+		r.Get("/inventories", noContent)
+	})
+	return router
+}
+
+// rateLimitHeaders is a response's RateLimit and Retry-After headers.
+func rateLimitHeaders(header http.Header) map[string]string {
+	headers := map[string]string{}
+	for _, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"} {
+		if value := header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+func TestRateLimitRefill(t *testing.T) {
+	useTestStore(t)
+	_, clock := useTestRateLimiter(t, "GET /v1/api/rack=2/m, *=100/m", 0, 0)
+	router := limitedRouter()
+	get := func() (int, map[string]string) {
+		rec := serveRequest(router, "GET", "/v1/api/rack", "", "")
+		return rec.Code, rateLimitHeaders(rec.Header())
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		status  int
+		headers map[string]string
+	}{
+		{"first", 0, http.StatusNoContent, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "30", "RateLimit-Policy": "2;w=60"}},
+		{"second", 0, http.StatusNoContent, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "60", "RateLimit-Policy": "2;w=60"}},
+		{"empty", 0, http.StatusTooManyRequests, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "60", "RateLimit-Policy": "2;w=60", "Retry-After": "30"}},
+		{"part refilled", 20 * time.Second, http.StatusTooManyRequests, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "40", "RateLimit-Policy": "2;w=60", "Retry-After": "10"}},
+		{"one token back", 10 * time.Second, http.StatusNoContent, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "60", "RateLimit-Policy": "2;w=60"}},
+		{"refilled, not past full", 10 * time.Minute, http.StatusNoContent, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "30", "RateLimit-Policy": "2;w=60"}},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		status, headers := get()
+		if status != step.status {
+			t.Errorf("%s: status = %d, want %d", step.name, status, step.status)
+		}
+		if !maps.Equal(headers, step.headers) {
+			t.Errorf("%s: headers = %v, want %v", step.name, headers, step.headers)
+		}
+	}
+}
+
+func TestRateLimitResponse(t *testing.T) {
+	useTestStore(t)
+	useTestRateLimiter(t, "GET /v1/api/rack=1/m, *=100/m", 0, 0)
+	router := limitedRouter()
+	limited := rateLimited.WithLabelValues("/v1/api/rack", "rate")
+	before := counterValue(t, limited)
+
+	serveRequest(router, "GET", "/v1/api/rack", "", "")
+	rec := serveRequest(router, "GET", "/v1/api/rack", "", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := decodeError(t, rec); got.StatusText != "Too many requests." || got.ErrorText != "rate limit of 1 requests per 1m0s exceeded" {
+		t.Errorf("body = %+v", got)
+	}
+	if got := counterValue(t, limited) - before; got != 1 {
+		t.Errorf("rate limited requests counted = %v, want 1", got)
+	}
+
+	// 429s follow content negotiation like any other error
+	rec = serveRequest(router, "GET", "/v1/api/rack", "", "", "Accept", "application/yaml")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "error: rate limit of 1 requests per 1m0s exceeded") {
+		t.Errorf("YAML 429: %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestRateLimitClients(t *testing.T) {
+	useTestStore(t)
+	useTestRateLimiter(t, "GET /v1/api/rack=1/m, *=1/m", 0, 0)
+	router := limitedRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	// Each IP, each key and each limited route gets its own bucket
+	serve := func(remoteAddr, token, target string) int {
+		rec := serveRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.RemoteAddr = remoteAddr
+			router.ServeHTTP(w, r)
+		}), "GET", target, "", token)
+		return rec.Code
+	}
+	requests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		target     string
+		want       int
+	}{
+		{"first IP", "192.0.2.1:1000", "", "/v1/api/rack", http.StatusNoContent},
+		{"first IP, another port", "192.0.2.1:1001", "", "/v1/api/rack", http.StatusTooManyRequests},
+		{"second IP", "192.0.2.2:1000", "", "/v1/api/rack", http.StatusNoContent},
+		{"key from the first IP", "192.0.2.1:1000", token, "/v1/api/rack", http.StatusNoContent},
+		{"key from another IP", "192.0.2.3:1000", token, "/v1/api/rack", http.StatusTooManyRequests},
+		{"another route", "192.0.2.1:1000", "", "/v1/api/inventories", http.StatusNoContent},
+		{"another route again", "192.0.2.1:1000", "", "/v1/api/inventories", http.StatusTooManyRequests},
+	}
+	for _, req := range requests {
+		if got := serve(req.remoteAddr, req.token, req.target); got != req.want {
+			t.Errorf("%s: status = %d, want %d", req.name, got, req.want)
+		}
+	}
+}
+
+func TestDailyQuota(t *testing.T) {
+	useTestStore(t)
+	limiter, clock := useTestRateLimiter(t, "*=100/m", 2, 0)
+	router := limitedRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+	clock.Advance(11*time.Hour + 59*time.Minute) // 23:59 UTC
+
+	for i := 0; i < 2; i++ {
+		if rec := serveRequest(router, "GET", "/v1/api/rack", "", token); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: %d", i+1, rec.Code)
+		}
+	}
+	rec := serveRequest(router, "GET", "/v1/api/rack", "", token)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the quota: %d, want 429", rec.Code)
+	}
+	if got := decodeError(t, rec).ErrorText; got != "daily quota of 2 requests used up, it resets at midnight UTC" {
+		t.Errorf("error = %q", got)
+	}
+	// The quota is closer to running out than the rate limit, so the headers describe it
+	want := map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "60", "RateLimit-Policy": "100;w=60, 2;w=86400", "Retry-After": "60"}
+	if got := rateLimitHeaders(rec.Header()); !maps.Equal(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+	if usage := limiter.Usage(); len(usage) != 1 || usage[0].Date != "2026-10-19" || usage[0].Requests != 2 || usage[0].Quota != 2 {
+		t.Errorf("usage = %+v, want 2 requests on 2026-10-19", usage)
+	}
+
+	// Turned away by the quota, the request didn't spend a rate token
+	limiter.mu.Lock()
+	var tokens float64
+	for _, bucket := range limiter.buckets {
+		tokens = bucket.tokens
+	}
+	limiter.mu.Unlock()
+	if tokens != 98 {
+		t.Errorf("rate tokens left = %v, want 98", tokens)
+	}
+
+	// Midnight UTC starts a new day
+	clock.Advance(time.Minute)
+	rec = serveRequest(router, "GET", "/v1/api/rack", "", token)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Remaining") != "1" || rec.Header().Get("RateLimit-Reset") != "86400" {
+		t.Errorf("after midnight: %d, headers %v", rec.Code, rateLimitHeaders(rec.Header()))
+	}
+	if usage := limiter.Usage(); len(usage) != 1 || usage[0].Date != "2026-10-20" || usage[0].Requests != 1 {
+		t.Errorf("usage = %+v, want 1 request on 2026-10-20", usage)
+	}
+}
+
+func TestRateLimitSweep(t *testing.T) {
+	useTestStore(t)
+	limiter, clock := useTestRateLimiter(t, "*=2/m", 0, 5*time.Millisecond)
+	router := limitedRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+	clock.Advance(11*time.Hour + 59*time.Minute + 30*time.Second) // 23:59:30 UTC
+
+	serveRequest(router, "GET", "/v1/api/rack", "", "")
+	serveRequest(router, "GET", "/v1/api/rack", "", token)
+	serveRequest(router, "GET", "/v1/api/rack", "", token)
+	counts := func() (buckets, usage int) {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		return len(limiter.buckets), len(limiter.usage)
+	}
+
+	// Buckets still refilling and today's usage stay
+	time.Sleep(20 * time.Millisecond)
+	if buckets, usage := counts(); buckets != 2 || usage != 1 {
+		t.Fatalf("before refilling: %d buckets, %d usage, want 2 and 1", buckets, usage)
+	}
+
+	// 30s refills the IP's bucket but not the key's, and it's a new day
+	clock.Advance(30 * time.Second)
+	eventually(t, func() bool { buckets, usage := counts(); return buckets == 1 && usage == 0 }, "sweeping the full bucket and yesterday's usage")
+	clock.Advance(time.Minute)
+	eventually(t, func() bool { buckets, _ := counts(); return buckets == 0 }, "sweeping the refilled bucket")
+
+	// A swept client starts over with a full bucket
+	rec := serveRequest(router, "GET", "/v1/api/rack", "", token)
+	if rec.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("RateLimit-Remaining after the sweep = %q, want 1", rec.Header().Get("RateLimit-Remaining"))
+	}
+}
+
+func TestGraphQLRateLimit(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, "POST /graphql=10/m, *=100/m", 0, 0)
+	router := chi.NewRouter()
+	router.With(Authenticate, rateLimiter.Limit).Post("/graphql", GraphQLHandler)
+	query := func(aliases int) string {
+		body, _ := json.Marshal(GraphQLRequest{Query: "{" + strings.Repeat(" r: rack(weight: 135) { achievedWeight }", aliases) + " }"})
+		return string(body)
+	}
+
+	// Each calculation costs one request of the limit
+	rec := serveRequest(router, "POST", "/graphql", query(4), "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "6" {
+		t.Fatalf("4 calculations: %d, RateLimit-Remaining %q, want 6", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+	rec = serveRequest(router, "POST", "/graphql", query(7), "")
+	if rec.Code != http.StatusTooManyRequests || decodeError(t, rec).ErrorText != "rate limit of 10 requests per 1m0s exceeded" {
+		t.Errorf("7 calculations with 6 left: %d %s", rec.Code, rec.Body)
+	}
+	if rec := serveRequest(router, "POST", "/graphql", query(5), ""); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("5 calculations with 5 left: %d, RateLimit-Remaining %q", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+
+	// A query that can never fit says so
+	useTestRateLimiter(t, "POST /graphql=10/m, *=100/m", 0, 0)
+	router = chi.NewRouter()
+	router.With(Authenticate, rateLimiter.Limit).Post("/graphql", GraphQLHandler)
+	rec = serveRequest(router, "POST", "/graphql", query(12), "")
+	if rec.Code != http.StatusTooManyRequests || decodeError(t, rec).ErrorText != "request costs more than the rate limit of 10 requests per 1m0s allows" {
+		t.Errorf("12 calculations: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCalculationRateLimit(t *testing.T) {
+	useTestStore(t)
+	useTestCache(t, CacheConfig{TTL: time.Minute})
+	useTestRateLimiter(t, defaultRateLimits, 0, 0)
+	calculationRouter := func() http.Handler {
+		router := chi.NewRouter()
+		router.Route("/v1/api", func(r chi.Router) {
+			r.Use(Authenticate, rateLimiter.Limit)
+			r.Post("/rack/batch", RackEmBatch)
+			r.Get("/rack/chart", RackEmChart)
+			r.Post("/sessions/pdf", RackEmSessionPDF)
+		})
+		return router
+	}
+	router := calculationRouter()
+
+	// Each row costs one request, and the largest chart uses up the default limit
+	rec := serveRequest(router, "GET", "/v1/api/rack/chart?from=46&to=1045&step=1", "", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("1000 rows: %d, RateLimit-Remaining %q, want 0", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+	rec = serveRequest(router, "GET", "/v1/api/rack/chart?to=45", "", "")
+	if rec.Code != http.StatusTooManyRequests || decodeError(t, rec).ErrorText != "rate limit of 1000 requests per 10m0s exceeded" {
+		t.Errorf("a row after 1000: %d %s", rec.Code, rec.Body)
+	}
+
+	// So do batch items and session sheet sets
+	rec = serveRequest(router, "POST", "/v1/api/rack/batch", `[{"desiredWeight": 135}, {"desiredWeight": 225}, "bad", {"desiredWeight": 315}]`, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "96" {
+		t.Errorf("4 batch items: %d, RateLimit-Remaining %q, want 96", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+	set := `{"exercise": "Squat", "reps": 5, "weight": 225}`
+	rec = serveRequest(router, "POST", "/v1/api/sessions/pdf", `{"sets": [`+set+`, `+set+`, `+set+`]}`, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "297" {
+		t.Errorf("3 sets: %d, RateLimit-Remaining %q, want 297", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+
+	// A chart that can never fit says so
+	useTestRateLimiter(t, "GET /v1/api/rack/chart=10/m", 0, 0)
+	rec = serveRequest(calculationRouter(), "GET", "/v1/api/rack/chart?from=135&to=190", "", "")
+	if rec.Code != http.StatusTooManyRequests || decodeError(t, rec).ErrorText != "request costs more than the rate limit of 10 requests per 1m0s allows" {
+		t.Errorf("12 rows: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestUIRateLimit(t *testing.T) {
+	useTestStore(t)
+	useTestRateLimiter(t, "GET /ui=2/m, *=100/m", 0, 0)
+	router := chi.NewRouter()
+	router.Route("/ui", UIRoutes)
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	for i := 0; i < 2; i++ {
+		if rec := serveRequest(router, "GET", "/ui", "", ""); rec.Code != http.StatusOK {
+			t.Fatalf("GET /ui %d: %d", i+1, rec.Code)
+		}
+	}
+	rec := serveRequest(router, "GET", "/ui", "", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("third GET /ui: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Signed in, the page is limited by key instead of IP
+	rec = serveRequest(router, "GET", "/ui", "", "", "Cookie", uiKeyCookie+"="+token)
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("GET /ui signed in: %d, RateLimit-Remaining %q", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+	if rec := serveRequest(router, "GET", "/ui/inventories", "", ""); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "100" {
+		t.Errorf("GET /ui/inventories: %d, RateLimit-Limit %q", rec.Code, rec.Header().Get("RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrRecordNotFound is returned by the store when a record does not exist.
+var ErrRecordNotFound = errors.New("not found")
+
+// Store persists user data (inventory profiles, accounts, API keys and workouts)
+// as a single JSON document on disk. Every mutation rewrites the file atomically,
+// which is plenty for the amount of data gorack keeps and avoids pulling in a database.
+type Store struct {
+	path string
+	mu   sync.RWMutex
+	data storeData
+}
+
+// storeData is the on-disk layout of the store file.
+type storeData struct {
+	Inventories map[string]*Inventory `json:"inventories"`
+	Users       map[string]*User      `json:"users"`
+	APIKeys     map[string]*APIKey    `json:"apiKeys"`
+	Workouts    map[string]*Workout   `json:"workouts"`
+}
+
+// Global store instance
+var dataStore *Store
+
+// OpenStore loads the store from path, starting empty if the file does not exist yet.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &s.data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tables missing from older store files start out empty
+	if s.data.Inventories == nil {
+		s.data.Inventories = make(map[string]*Inventory)
+	}
+	if s.data.Users == nil {
+		s.data.Users = make(map[string]*User)
+	}
+	if s.data.APIKeys == nil {
+		s.data.APIKeys = make(map[string]*APIKey)
+	}
+	if s.data.Workouts == nil {
+		s.data.Workouts = make(map[string]*Workout)
+	}
+	return s, nil
+}
+
+// save writes the store to disk. Callers must hold the write lock.
+func (s *Store) save() error {
+	raw, err := json.MarshalIndent(&s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temp file first so a crash never leaves a half-written store
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// ListInventories returns all stored inventory profiles.
+func (s *Store) ListInventories() []*Inventory {
+	return listRecords(s, s.data.Inventories)
+}
+
+// GetInventory returns the inventory profile with the given ID.
+func (s *Store) GetInventory(id string) (*Inventory, error) {
+	return getRecord(s, s.data.Inventories, id)
+}
+
+// PutInventory creates or replaces an inventory profile.
+func (s *Store) PutInventory(inv *Inventory) error {
+	return putRecord(s, s.data.Inventories, inv.ID, inv)
+}
+
+// DeleteInventory removes an inventory profile.
+func (s *Store) DeleteInventory(id string) error {
+	return deleteRecord(s, s.data.Inventories, id)
+}
+
+// GetUser returns the user account with the given ID.
+func (s *Store) GetUser(id string) (*User, error) {
+	return getRecord(s, s.data.Users, id)
+}
+
+// PutUser creates or replaces a user account.
+func (s *Store) PutUser(user *User) error {
+	return putRecord(s, s.data.Users, user.ID, user)
+}
+
+// ListAPIKeys returns every API key belonging to a user, revoked ones included.
+func (s *Store) ListAPIKeys(userID string) []*APIKey {
+	keys := listRecords(s, s.data.APIKeys)
+	owned := keys[:0]
+	for _, key := range keys {
+		if key.UserID == userID {
+			owned = append(owned, key)
+		}
+	}
+	return owned
+}
+
+// GetAPIKey returns the API key with the given ID.
+func (s *Store) GetAPIKey(id string) (*APIKey, error) {
+	return getRecord(s, s.data.APIKeys, id)
+}
+
+// PutAPIKey creates or replaces an API key.
+func (s *Store) PutAPIKey(key *APIKey) error {
+	return putRecord(s, s.data.APIKeys, key.ID, key)
+}
+
+// ListWorkouts returns every workout session logged by a user.
+func (s *Store) ListWorkouts(userID string) []*Workout {
+	workouts := listRecords(s, s.data.Workouts)
+	owned := workouts[:0]
+	for _, workout := range workouts {
+		if workout.UserID == userID {
+			owned = append(owned, workout)
+		}
+	}
+	return owned
+}
+
+// GetWorkout returns the workout session with the given ID.
+func (s *Store) GetWorkout(id string) (*Workout, error) {
+	return getRecord(s, s.data.Workouts, id)
+}
+
+// PutWorkout creates or replaces a workout session.
+func (s *Store) PutWorkout(workout *Workout) error {
+	return putRecord(s, s.data.Workouts, workout.ID, workout)
+}
+
+// DeleteWorkout removes a workout session.
+func (s *Store) DeleteWorkout(id string) error {
+	return deleteRecord(s, s.data.Workouts, id)
+}
+
+// listRecords returns copies of every record in one of the store's tables.
+func listRecords[T any](s *Store, table map[string]*T) []*T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := make([]*T, 0, len(table))
+	for _, record := range table {
+		copied := *record
+		records = append(records, &copied)
+	}
+	return records
+}
+
+// getRecord returns a copy of a single record so callers can't mutate the store directly.
+func getRecord[T any](s *Store, table map[string]*T, id string) (*T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, found := table[id]
+	if !found {
+		return nil, ErrRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// putRecord stores a copy of a record and persists the store.
+func putRecord[T any](s *Store, table map[string]*T, id string, record *T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	previous, existed := table[id]
+	table[id] = &copied
+	if err := s.save(); err != nil {
+		// Roll back so memory never disagrees with disk
+		if existed {
+			table[id] = previous
+		} else {
+			delete(table, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteRecord removes a record and persists the store.
+func deleteRecord[T any](s *Store, table map[string]*T, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, found := table[id]
+	if !found {
+		return ErrRecordNotFound
+	}
+	delete(table, id)
+	if err := s.save(); err != nil {
+		table[id] = previous
+		return err
+	}
+	return nil
+}
+
+// newID generates a random identifier for stored records.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// LoadingTable holds a precomputed result for every loadable weight with the
+// default bar and plates, for each strategy. GET /rack without an inventory
+// only ever asks about the defaults, so it's answered from here.
+type LoadingTable struct {
+	mu         sync.RWMutex
+	defaults   rack.RackInputStandard
+	loadings   map[string][]*rack.ReturnedValueStandard // By strategy, indexed by weight - bar weight - 1
+	generation int                                      // Bumped on every build so stale builds are dropped
+}
+
+// Global lookup table for the default plates
+var defaultTable = &LoadingTable{}
+
+// Build computes the table for defaults. The default strategy is ready when
+// Build returns; the slower search strategies finish in the background, and
+// lookups for them fall through to the cache until they do.
+func (t *LoadingTable) Build(defaults rack.RackInputStandard) {
+	t.mu.Lock()
+	t.generation++
+	generation := t.generation
+	t.defaults = defaults
+	t.loadings = map[string][]*rack.ReturnedValueStandard{}
+	t.mu.Unlock()
+
+	t.buildStrategy(generation, defaults, rack.DefaultStrategy)
+	for _, strategy := range rack.Strategies() {
+		if strategy != rack.DefaultStrategy {
+			go t.buildStrategy(generation, defaults, strategy)
+		}
+	}
+}
+
+// buildStrategy solves every weight from just over the bar up to everything
+// loaded, then publishes the results unless a newer build has started.
+func (t *LoadingTable) buildStrategy(generation int, defaults rack.RackInputStandard, strategy string) {
+	start := time.Now()
+	heaviest := maxLoadable(&defaults)
+	loadings := make([]*rack.ReturnedValueStandard, 0, max(heaviest-defaults.BarWeight, 0))
+	for weight := defaults.BarWeight + 1; weight <= heaviest; weight++ {
+		input := defaults
+		input.DesiredWeight = weight
+		input.Strategy = strategy
+		result, err := rack.CalculateWeight(&input)
+		if err != nil {
+			slog.Error("Building loading table failed", "strategy", strategy, "weight", weight, "err", err)
+			return
+		}
+		loadings = append(loadings, result)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.generation != generation {
+		return
+	}
+	t.loadings[strategy] = loadings
+	slog.Info("Built loading table", "strategy", strategy, "weights", len(loadings), "duration", time.Since(start).Round(time.Millisecond).String())
+}
+
+// Lookup returns the precomputed result for weight with the defaults the
+// table was built for. It misses for weights outside the table and for
+// strategies still being built.
+func (t *LoadingTable) Lookup(strategy string, weight int) (*rack.ReturnedValueStandard, bool) {
+	if strategy == "" {
+		strategy = rack.DefaultStrategy
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	loadings := t.loadings[strategy]
+	if i := weight - t.defaults.BarWeight - 1; i >= 0 && i < len(loadings) {
+		return loadings[i], true
+	}
+	return nil, false
+}
+
+// maxLoadable is the heaviest a bar can be loaded with input's plates.
+func maxLoadable(input *rack.RackInputStandard) int {
+	halves := 0 // Pairs of 1.25s add half pounds
+	for _, plateName := range rack.PlateOrder {
+		halves += int(rack.WeightAmounts[plateName]*4) * input.PlateCount(plateName)
+	}
+	return input.BarWeight + halves/2
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pachev/gorack/rack"
+)
+
+// smallDefaults is a plate set small enough to build every strategy quickly.
+func smallDefaults() rack.RackInputStandard {
+	return rack.RackInputStandard{BarWeight: 45, FortyFives: 2, TwentyFives: 1, Tens: 1, Fives: 1, TwoDotFives: 1}
+}
+
+func TestLoadingTable(t *testing.T) {
+	table := &LoadingTable{}
+	defaults := smallDefaults()
+	table.Build(defaults)
+	heaviest := maxLoadable(&defaults)
+	if heaviest != 45+180+50+20+10+5 {
+		t.Fatalf("maxLoadable = %d", heaviest)
+	}
+
+	for _, strategy := range rack.Strategies() {
+		eventually(t, func() bool {
+			_, found := table.Lookup(strategy, heaviest)
+			return found
+		}, strategy+" table wasn't built")
+	}
+
+	for weight := 46; weight <= heaviest; weight++ {
+		for _, strategy := range []string{"", "greedy", "exact"} {
+			got, found := table.Lookup(strategy, weight)
+			if !found {
+				t.Fatalf("%q at %d: not found", strategy, weight)
+			}
+			input := defaults
+			input.DesiredWeight, input.Strategy = weight, strategy
+			want, err := rack.CalculateWeight(&input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.AchievedWeight != want.AchievedWeight || got.Strategy != want.Strategy || got.LoadingOrder() == nil {
+				t.Fatalf("%q at %d = %+v, want %+v", strategy, weight, got, want)
+			}
+		}
+	}
+
+	for _, weight := range []int{0, 45, heaviest + 1} {
+		if _, found := table.Lookup("", weight); found {
+			t.Errorf("found a loading for %d, outside the table", weight)
+		}
+	}
+	if _, found := table.Lookup("no-such-strategy", 100); found {
+		t.Error("found a loading for an unknown strategy")
+	}
+}
+
+func TestTableHitsCountAsCacheHits(t *testing.T) {
+	cache := useTestCache(t, CacheConfig{TTL: time.Minute})
+	previous := defaultTable
+	// Only the default strategy, so the search strategies aren't built for minutes in the background
+	defaultTable = &LoadingTable{defaults: rack.AssumeDefaults(), loadings: map[string][]*rack.ReturnedValueStandard{}}
+	t.Cleanup(func() { defaultTable = previous })
+	defaultTable.buildStrategy(defaultTable.generation, defaultTable.defaults, rack.DefaultStrategy)
+
+	input := rack.AssumeDefaults()
+	input.DesiredWeight = 225
+	if _, hit, err := calculateDefault(context.Background(), &input, false); err != nil || !hit {
+		t.Fatalf("calculateDefault: hit %v, err %v", hit, err)
+	}
+	input.DesiredWeight = 9000 // Past the table, so the cache misses
+	if _, hit, err := calculateDefault(context.Background(), &input, false); err != nil || hit {
+		t.Fatalf("calculateDefault past the table: hit %v, err %v", hit, err)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.TableHits != 1 || stats.Misses != 1 || stats.HitRate != 0.5 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	rec := serveRequest(http.HandlerFunc(RackEmGet), "GET", "/v1/api/rack?weight=315", "", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("GET /rack from the table: %d, X-Cache %q", rec.Code, rec.Header().Get("X-Cache"))
+	}
+	if got := cache.Stats().TableHits; got != 2 {
+		t.Errorf("table hits = %d after GET /rack, want 2", got)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/pachev/gorack/rack"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts gorack's spans. It's a no-op until setupTracing installs an
+// exporter, so the CLI and WebAssembly builds never pay for tracing.
+var tracer = otel.Tracer("github.com/pachev/gorack")
+
+// setupTracing installs the exporter named by OTEL_TRACES_EXPORTER: "otlp"
+// sends spans over OTLP/HTTP to OTEL_EXPORTER_OTLP_ENDPOINT (a local
+// collector on port 4318 by default), "stdout" prints them, and "none"
+// (the default) turns tracing off. The other standard OTEL_* variables,
+// such as OTEL_SERVICE_NAME and OTEL_TRACES_SAMPLER, are honoured too.
+func setupTracing(ctx context.Context) error {
+	var processor sdktrace.SpanProcessor
+	switch exporter := getEnv("OTEL_TRACES_EXPORTER", "none"); exporter {
+	case "none":
+		return nil
+	case "otlp":
+		client, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(client)
+	case "stdout", "console":
+		// Printed as each span ends, so nothing is lost when the server stops
+		client, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return err
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(client)
+	default:
+		return fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q: must be otlp, stdout or none", exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("gorack")),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return err
+	}
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+	))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return nil
+}
+
+// Tracing starts a server span for each request, continuing the caller's
+// trace when it sends a traceparent header. It runs after
+// middleware.RequestID so the span carries the request ID, which ties log
+// lines to the trace.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				attribute.String("request.id", middleware.GetReqID(ctx)),
+			),
+		)
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route, status := routePattern(r), responseStatus(ww)
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// annotateRequest adds a calculation's input and whether it came from the
+// cache to the request's span.
+func annotateRequest(ctx context.Context, input *rack.RackInputStandard, hit bool) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(rackAttributes(input)...)
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+}
+
+// rackAttributes describes a calculation's input.
+func rackAttributes(input *rack.RackInputStandard) []attribute.KeyValue {
+	strategy := input.Strategy
+	if strategy == "" {
+		strategy = rack.DefaultStrategy
+	}
+	return []attribute.KeyValue{
+		attribute.Int("rack.desired_weight", input.DesiredWeight),
+		attribute.Int("rack.bar_weight", input.BarWeight),
+		attribute.String("rack.strategy", strategy),
+	}
+}
+
+// endSpan records err on span, if there is one, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pachev/gorack/rack"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// uiKeyCookie holds the API key the browser signed in with.
+const uiKeyCookie = "gorack_key"
+
+// uiTemplates are the web UI pages, each parsed together with the layout.
+var uiTemplates = map[string]*template.Template{
+	"calculator":  parseUITemplate("calculator"),
+	"inventories": parseUITemplate("inventories"),
+}
+
+func parseUITemplate(page string) *template.Template {
+	return template.Must(template.New(page).Funcs(template.FuncMap{
+		"plates":          func(plates []float32) string { return formatPlates(plates, " · ") },
+		"inventoryPlates": formatInventoryPlates,
+	}).ParseFS(webFiles, "web/templates/layout.html", "web/templates/"+page+".html"))
+}
+
+// uiPage is the data every UI page renders with.
+type uiPage struct {
+	Title     string
+	Active    string
+	Principal *Principal
+	Error     string
+	Form      uiForm
+
+	// Calculator
+	Bars        []rack.Bar
+	Strategies  []string
+	Inventories []*Inventory
+	Result      *rack.ReturnedValueStandard
+	PerSide     []float32
+	Diagram     *barDiagram
+}
+
+// uiForm is the calculator form as submitted, or its defaults.
+type uiForm struct {
+	Weight    string
+	Bar       string
+	Strategy  string
+	Inventory string
+	Plates    []uiPlateField
+}
+
+// uiPlateField is one plate's pair count input.
+type uiPlateField struct {
+	Field string // API field name, also the form field name
+	Label string
+	Pairs int
+}
+
+// plateFields lists the plate inputs for an input's pair counts.
+func plateFields(input *rack.RackInputStandard) []uiPlateField {
+	fields := make([]uiPlateField, len(rack.PlateOrder))
+	for i, plateName := range rack.PlateOrder {
+		fields[i] = uiPlateField{
+			Field: plateJSONNames[plateName],
+			Label: formatPlates([]float32{rack.WeightAmounts[plateName]}, "") + " lb",
+			Pairs: input.PlateCount(plateName),
+		}
+	}
+	return fields
+}
+
+// formatInventoryPlates lists an inventory's pairs, for example "2×45 · 1×10".
+func formatInventoryPlates(inv *Inventory) string {
+	input := rack.RackInputStandard{}
+	inv.Apply(&input)
+	pairs := []string{}
+	for _, plateName := range rack.PlateOrder {
+		if count := input.PlateCount(plateName); count > 0 {
+			pairs = append(pairs, fmt.Sprintf("%d×%s", count, formatPlates([]float32{rack.WeightAmounts[plateName]}, "")))
+		}
+	}
+	if len(pairs) == 0 {
+		return "none"
+	}
+	return strings.Join(pairs, " · ")
+}
+
+// UIRoutes mounts the browser UI: the calculator, saved inventories and the
+// static files they use.
+func UIRoutes(r chi.Router) {
+	static, err := fs.Sub(webFiles, "web/static")
+	if err != nil {
+		fatal("Loading web UI files failed", "err", err)
+	}
+	r.Handle("/static/*", http.StripPrefix("/ui/static/", http.FileServer(http.FS(static))))
+
+	// Pages are rate limited like the API, by the signed-in key or the IP
+	r.Group(func(r chi.Router) {
+		r.Use(uiAuthenticate, rateLimiter.Limit)
+		r.Get("/", UICalculator)
+		r.Get("/inventories", UIInventories)
+		r.Post("/inventories", UICreateInventory)
+		r.Post("/inventories/{inventoryID}/delete", UIDeleteInventory)
+		r.Post("/session", UISignIn)
+		r.Post("/session/delete", UISignOut)
+	})
+}
+
+// uiAuthenticate is Authenticate for the browser: the API key comes from a
+// cookie. A key that no longer works is cleared and the request continues
+// anonymously.
+func uiAuthenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(uiKeyCookie)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, err := authenticateToken(cookie.Value)
+		if err != nil {
+			setKeyCookie(w, r, "")
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := context.WithValue(r.Context(), principalContextKey{}, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// setKeyCookie stores the API key in the session cookie, or clears it when key is empty.
+func setKeyCookie(w http.ResponseWriter, r *http.Request, key string) {
+	cookie := &http.Cookie{
+		Name:     uiKeyCookie,
+		Value:    key,
+		Path:     "/ui",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode, // Also keeps other sites from posting the forms
+	}
+	if key == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// renderUI writes a UI page, logging template errors since the headers are gone by then.
+func renderUI(w http.ResponseWriter, r *http.Request, status int, page string, data *uiPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := uiTemplates[page].ExecuteTemplate(w, "layout", data); err != nil {
+		slog.ErrorContext(r.Context(), "Rendering UI page failed", "page", page, "err", err)
+	}
+}
+
+// UICalculator shows the calculator form and, once a weight is submitted,
+// the plates to load and a diagram of the loaded bar.
+func UICalculator(w http.ResponseWriter, r *http.Request) {
+	principal := PrincipalFrom(r.Context())
+	page := &uiPage{
+		Title:      "Calculator",
+		Active:     "calculator",
+		Principal:  principal,
+		Bars:       rack.Bars,
+		Strategies: rack.Strategies(),
+	}
+	if principal != nil {
+		page.Inventories = accessibleInventories(principal)
+	}
+
+	query := r.URL.Query()
+	page.Form = uiForm{
+		Weight:    query.Get("weight"),
+		Bar:       query.Get("bar"),
+		Strategy:  query.Get("strategy"),
+		Inventory: query.Get("inventory"),
+	}
+	if page.Form.Strategy == "" {
+		page.Form.Strategy = rack.DefaultStrategy
+	}
+
+	plates := rack.AssumeDefaults()
+	for _, plateName := range rack.PlateOrder {
+		if value := query.Get(plateJSONNames[plateName]); value != "" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				page.Error = "Plate counts must be whole numbers"
+			}
+			plates.SetPlateCount(plateName, count)
+		}
+	}
+	if page.Form.Inventory != "" {
+		inv, err := lookupInventory(r.Context(), page.Form.Inventory)
+		if err != nil {
+			page.Form.Plates = plateFields(&plates)
+			renderUI(w, r, http.StatusNotFound, "calculator", withError(page, "Inventory not found"))
+			return
+		}
+		if page.Form.Bar == "" {
+			plates.BarWeight = 0 // Use the inventory's bar rather than the default one
+		}
+		inv.Apply(&plates)
+	}
+	page.Form.Plates = plateFields(&plates)
+
+	status := http.StatusOK
+	switch {
+	case page.Error != "":
+		status = http.StatusBadRequest
+	case getEnvBool("AUTH_REQUIRED", false) && !principal.HasScope(ScopeRead):
+		status, page.Error = http.StatusUnauthorized, "Sign in with an API key that has the '"+ScopeRead+"' scope to run calculations"
+	case page.Form.Weight != "":
+		status = page.calculate(r.Context(), plates)
+	}
+	renderUI(w, r, status, "calculator", page)
+}
+
+// calculate runs the submitted form and fills in the result, returning the
+// response status.
+func (page *uiPage) calculate(ctx context.Context, plates rack.RackInputStandard) int {
+	weight, err := strconv.Atoi(page.Form.Weight)
+	if err != nil {
+		page.Error = "Weight must be a whole number of pounds"
+		return http.StatusBadRequest
+	}
+	if page.Form.Bar != "" {
+		if plates.BarWeight, err = strconv.Atoi(page.Form.Bar); err != nil {
+			page.Error = "Bar weight must be a whole number of pounds"
+			return http.StatusBadRequest
+		}
+	}
+
+	// The plates already hold the inventory's pairs, so the query doesn't need it again
+	query := &RackQuery{Weight: weight, Plates: &plates, Strategy: page.Form.Strategy}
+	result, errResponse := query.Calculate(ctx)
+	if errResponse != nil {
+		page.Error = errResponse.ErrorText
+		return errResponse.HTTPStatusCode
+	}
+	page.Result = result
+	page.PerSide = result.LoadingOrder()
+	page.Diagram = newBarDiagram(page.PerSide)
+	return http.StatusOK
+}
+
+// withError sets a page's error message.
+func withError(page *uiPage, message string) *uiPage {
+	page.Error = message
+	return page
+}
+
+// UIInventories lists the signed-in user's inventories with a form to add
+// one, or asks for an API key.
+func UIInventories(w http.ResponseWriter, r *http.Request) {
+	renderUI(w, r, http.StatusOK, "inventories", inventoriesPage(r))
+}
+
+func inventoriesPage(r *http.Request) *uiPage {
+	principal := PrincipalFrom(r.Context())
+	page := &uiPage{
+		Title:     "Inventories",
+		Active:    "inventories",
+		Principal: principal,
+		Form:      uiForm{Plates: plateFields(&rack.RackInputStandard{})},
+	}
+	if principal != nil {
+		page.Inventories = accessibleInventories(principal)
+	}
+	return page
+}
+
+// UICreateInventory saves an inventory from the UI form, the same way CreateInventory does.
+func UICreateInventory(w http.ResponseWriter, r *http.Request) {
+	principal := PrincipalFrom(r.Context())
+	if !principal.HasScope(ScopeWrite) {
+		renderUI(w, r, http.StatusForbidden, "inventories", withError(inventoriesPage(r), "Your API key needs the '"+ScopeWrite+"' scope to save inventories"))
+		return
+	}
+
+	input := &InventoryRequest{Name: r.PostFormValue("name")}
+	fields := map[string]*int{
+		"barWeight":      &input.BarWeight,
+		"hundreds":       &input.Hundos,
+		"fortyFives":     &input.FortyFives,
+		"thirtyFives":    &input.ThirtyFives,
+		"twentyFives":    &input.TwentyFives,
+		"tens":           &input.Tens,
+		"fives":          &input.Fives,
+		"twoDotFives":    &input.TwoDotFives,
+		"oneDotTwoFives": &input.OneDotTwoFives,
+	}
+	for field, count := range fields {
+		value := r.PostFormValue(field)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			renderUI(w, r, http.StatusBadRequest, "inventories", withError(inventoriesPage(r), "Bar weight and plate counts must be whole numbers"))
+			return
+		}
+		*count = n
+	}
+	if err := input.Bind(r); err != nil {
+		renderUI(w, r, http.StatusBadRequest, "inventories", withError(inventoriesPage(r), err.Error()))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Generating inventory ID failed", "err", err)
+		renderUI(w, r, http.StatusInternalServerError, "inventories", withError(inventoriesPage(r), "Internal server error"))
+		return
+	}
+	now := time.Now().UTC()
+	inv := &Inventory{ID: id, OwnerID: principal.User.ID, CreatedAt: now, UpdatedAt: now}
+	input.applyTo(inv)
+	if err := dataStore.PutInventory(inv); err != nil {
+		slog.ErrorContext(r.Context(), "Saving inventory failed", "err", err)
+		renderUI(w, r, http.StatusInternalServerError, "inventories", withError(inventoriesPage(r), "Internal server error"))
+		return
+	}
+	http.Redirect(w, r, "/ui/inventories", http.StatusSeeOther)
+}
+
+// UIDeleteInventory deletes one of the signed-in user's inventories.
+func UIDeleteInventory(w http.ResponseWriter, r *http.Request) {
+	principal := PrincipalFrom(r.Context())
+	if !principal.HasScope(ScopeWrite) {
+		renderUI(w, r, http.StatusForbidden, "inventories", withError(inventoriesPage(r), "Your API key needs the '"+ScopeWrite+"' scope to delete inventories"))
+		return
+	}
+	inv, err := lookupInventory(r.Context(), chi.URLParam(r, "inventoryID"))
+	if err != nil || inv.OwnerID != principal.User.ID {
+		renderUI(w, r, http.StatusNotFound, "inventories", withError(inventoriesPage(r), "Inventory not found"))
+		return
+	}
+	if err := dataStore.DeleteInventory(inv.ID); err != nil && !errors.Is(err, ErrRecordNotFound) {
+		slog.ErrorContext(r.Context(), "Deleting inventory failed", "inventory", inv.ID, "err", err)
+		renderUI(w, r, http.StatusInternalServerError, "inventories", withError(inventoriesPage(r), "Internal server error"))
+		return
+	}
+	http.Redirect(w, r, "/ui/inventories", http.StatusSeeOther)
+}
+
+// UISignIn checks an API key and keeps it in the session cookie.
+func UISignIn(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PostFormValue("key"))
+	principal, err := authenticateToken(key)
+	if err != nil {
+		renderUI(w, r, http.StatusUnauthorized, "inventories", withError(inventoriesPage(r), "Couldn't sign in: "+err.Error()))
+		return
+	}
+	slog.InfoContext(r.Context(), "Web UI sign-in", "user", principal.User.ID, "key", principal.Key.ID)
+	setKeyCookie(w, r, key)
+	http.Redirect(w, r, "/ui/inventories", http.StatusSeeOther)
+}
+
+// UISignOut clears the session cookie.
+func UISignOut(w http.ResponseWriter, r *http.Request) {
+	setKeyCookie(w, r, "")
+	http.Redirect(w, r, "/ui", http.StatusSeeOther)
+}
+
+// barDiagram is the SVG drawing of a loaded bar, with the same plates on
+// each side of the centre.
+type barDiagram struct {
+	Width, Height           int
+	ShaftY, ShaftWidth      float64
+	CollarY                 float64
+	LeftCollar, RightCollar float64
+	Plates                  []diagramPlate
+}
+
+// diagramPlate is one plate drawn on the bar.
+type diagramPlate struct {
+	X, Y, Width, Height float64
+	Fill                string
+	Label               string
+}
+
+// plateColors follow the usual bumper plate colours.
+var plateColors = map[float32]string{
+	100:  "#333333",
+	45:   "#1f5fbf",
+	35:   "#e0b400",
+	25:   "#2e8b57",
+	10:   "#dddddd",
+	5:    "#c0392b",
+	2.5:  "#555555",
+	1.25: "#999999",
+}
+
+// Diagram layout, in SVG units.
+const (
+	diagramWidth   = 640
+	diagramHeight  = 200
+	diagramSleeve  = 110 // Distance from the centre to each collar
+	diagramMargin  = 10
+	diagramGap     = 2
+	diagramCollarW = 10
+)
+
+// newBarDiagram lays out perSide on both sleeves, heaviest plates nearest
+// the collars. Plates are sized like the PDF diagram and squeezed to fit.
+func newBarDiagram(perSide []float32) *barDiagram {
+	centre := float64(diagramWidth) / 2
+	d := &barDiagram{
+		Width:       diagramWidth,
+		Height:      diagramHeight,
+		ShaftY:      float64(diagramHeight)/2 - 4,
+		ShaftWidth:  diagramWidth - 2*diagramMargin,
+		CollarY:     float64(diagramHeight)/2 - 14,
+		LeftCollar:  centre - diagramSleeve - diagramCollarW,
+		RightCollar: centre + diagramSleeve,
+	}
+
+	widths := make([]float64, len(perSide))
+	total := 0.0
+	for i, plate := range perSide {
+		widths[i] = 14
+		if plate < 25 {
+			widths[i] = 9
+		}
+		total += widths[i] + diagramGap
+	}
+	room := float64(diagramWidth) - diagramMargin - (d.RightCollar + diagramCollarW)
+	scale := 1.0
+	if total > room {
+		scale = room / total
+	}
+
+	offset := 0.0
+	for i, plate := range perSide {
+		width := math.Round(widths[i]*scale*10) / 10
+		height := diagramHeight * (0.3 + 0.7*float64(plate)/100)
+		if plate >= 45 {
+			height = diagramHeight
+		}
+		height = math.Round(height*10) / 10
+		y := (float64(diagramHeight) - height) / 2
+		label := formatPlates([]float32{plate}, "")
+		right := d.RightCollar + diagramCollarW + offset
+		left := d.LeftCollar - offset - width
+		d.Plates = append(d.Plates,
+			diagramPlate{X: left, Y: y, Width: width, Height: height, Fill: plateColors[plate], Label: label},
+			diagramPlate{X: right, Y: y, Width: width, Height: height, Fill: plateColors[plate], Label: label},
+		)
+		offset += width + math.Round(diagramGap*scale*10)/10
+	}
+	return d
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// User is an account that owns API keys and saved data.
+type User struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APIKey is a stored API key. Only the hash of its secret is kept.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	SecretHash string     `json:"secretHash"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// APIKeyResponse is the public view of an API key. Key holds the plaintext
+// token and is only set in the response that creates it.
+type APIKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Key       string     `json:"key,omitempty"`
+}
+
+// NewAPIKeyResponse builds the public view of a stored key.
+func NewAPIKeyResponse(key *APIKey, token string) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+		Key:       token,
+	}
+}
+
+// SignupResponse is returned when a new account is created.
+type SignupResponse struct {
+	User   *User           `json:"user"`
+	APIKey *APIKeyResponse `json:"apiKey"`
+}
+
+// UserRequest is the payload for creating an account.
+type UserRequest struct {
+	Name string `json:"name"`
+}
+
+// Bind validates an account payload.
+func (ur *UserRequest) Bind(r *http.Request) error {
+	ur.Name = strings.TrimSpace(ur.Name)
+	if ur.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// APIKeyRequest is the payload for creating an API key.
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// Bind validates an API key payload. Keys default to read-only.
+func (kr *APIKeyRequest) Bind(r *http.Request) error {
+	kr.Name = strings.TrimSpace(kr.Name)
+	if kr.Name == "" {
+		return errors.New("key name is required")
+	}
+	if len(kr.Scopes) == 0 {
+		kr.Scopes = []string{ScopeRead}
+	}
+	principal := PrincipalFrom(r.Context())
+	for _, scope := range kr.Scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return errors.New("unknown scope: " + scope)
+		}
+		// A key can't hand out more access than it has itself
+		if !principal.HasScope(scope) {
+			return errors.New("cannot grant scope '" + scope + "' that the current key does not have")
+		}
+	}
+	return nil
+}
+
+// UserRoutes mounts the account endpoints.
+func UserRoutes(r chi.Router) {
+	r.Post("/", CreateUser)
+	r.With(RequireScope(ScopeRead)).Get("/me", GetCurrentUser)
+}
+
+// APIKeyRoutes mounts the API key management endpoints.
+func APIKeyRoutes(r chi.Router) {
+	r.Use(RequireScope(ScopeKeys))
+	r.Get("/", ListAPIKeys)
+	r.Post("/", CreateAPIKey)
+	r.Post("/{keyID}/rotate", RotateAPIKey)
+	r.Delete("/{keyID}", RevokeAPIKey)
+}
+
+// CreateUser godoc
+// @Summary      Create an account
+// @Description  Creates a user and returns its first API key with every scope. The key is only shown once. Needs the admin key unless ALLOW_SIGNUP is set.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      UserRequest  true  "Account name"
+// @Success      201      {object}  SignupResponse
+// @Failure      400      {object}  ErrResponse
+// @Failure      403      {object}  ErrResponse
+// @Failure      500      {object}  ErrResponse
+// @Router       /users [post]
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	if !getEnvBool("ALLOW_SIGNUP", false) && !isAdmin(r) {
+		render.Render(w, r, ErrForbidden(errors.New("signups are disabled on this server; ask an operator for an account")))
+		return
+	}
+
+	input := &UserRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Generating user ID failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	user := &User{ID: id, Name: input.Name, CreatedAt: time.Now().UTC()}
+	if err := dataStore.PutUser(user); err != nil {
+		slog.ErrorContext(r.Context(), "Saving user failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	key, token, err := issueAPIKey(user.ID, "default", AllScopes)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Issuing API key failed", "user", user.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &SignupResponse{User: user, APIKey: NewAPIKeyResponse(key, token)})
+}
+
+// GetCurrentUser godoc
+// @Summary      Get the current account
+// @Tags         Auth
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  User
+// @Failure      401  {object}  ErrResponse
+// @Router       /users/me [get]
+func GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, PrincipalFrom(r.Context()).User)
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  Returns every key on the account, including revoked ones
+// @Tags         Auth
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   APIKeyResponse
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Router       /keys [get]
+func ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys := dataStore.ListAPIKeys(PrincipalFrom(r.Context()).User.ID)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	responses := make([]*APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, NewAPIKeyResponse(key, ""))
+	}
+	render.JSON(w, r, responses)
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Issues a new key with a subset of the current key's scopes. The key is only shown once.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      APIKeyRequest  true  "Key name and scopes"
+// @Success      201      {object}  APIKeyResponse
+// @Failure      400      {object}  ErrResponse
+// @Failure      401      {object}  ErrResponse
+// @Failure      403      {object}  ErrResponse
+// @Failure      500      {object}  ErrResponse
+// @Router       /keys [post]
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	input := &APIKeyRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	key, token, err := issueAPIKey(PrincipalFrom(r.Context()).User.ID, input.Name, input.Scopes)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Issuing API key failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, NewAPIKeyResponse(key, token))
+}
+
+// RotateAPIKey godoc
+// @Summary      Rotate an API key
+// @Description  Revokes the key and issues a replacement with the same name and scopes
+// @Tags         Auth
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        keyID  path      string  true  "API key ID"
+// @Success      201    {object}  APIKeyResponse
+// @Failure      401    {object}  ErrResponse
+// @Failure      403    {object}  ErrResponse
+// @Failure      404    {object}  ErrResponse
+// @Failure      500    {object}  ErrResponse
+// @Router       /keys/{keyID}/rotate [post]
+func RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := lookupAPIKey(w, r)
+	if !ok {
+		return
+	}
+	if key.RevokedAt != nil {
+		render.Render(w, r, ErrInvalidRequest(errors.New("cannot rotate a revoked key")))
+		return
+	}
+
+	replacement, token, err := issueAPIKey(key.UserID, key.Name, key.Scopes)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Issuing replacement API key failed", "key", key.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	if err := revokeAPIKey(key); err != nil {
+		slog.ErrorContext(r.Context(), "Revoking rotated API key failed", "key", key.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, NewAPIKeyResponse(replacement, token))
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Tags         Auth
+// @Security     ApiKeyAuth
+// @Param        keyID  path  string  true  "API key ID"
+// @Success      204
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Failure      404  {object}  ErrResponse
+// @Failure      500  {object}  ErrResponse
+// @Router       /keys/{keyID} [delete]
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := lookupAPIKey(w, r)
+	if !ok {
+		return
+	}
+	if key.RevokedAt == nil {
+		if err := revokeAPIKey(key); err != nil {
+			slog.ErrorContext(r.Context(), "Revoking API key failed", "key", key.ID, "err", err)
+			render.Render(w, r, ErrInternal())
+			return
+		}
+	}
+	render.NoContent(w, r)
+}
+
+// lookupAPIKey loads the key named in the URL, rendering a 404 if it
+// doesn't exist or belongs to someone else.
+func lookupAPIKey(w http.ResponseWriter, r *http.Request) (*APIKey, bool) {
+	key, err := dataStore.GetAPIKey(chi.URLParam(r, "keyID"))
+	if err != nil || key.UserID != PrincipalFrom(r.Context()).User.ID {
+		render.Render(w, r, ErrNotFound(errors.New("API key not found")))
+		return nil, false
+	}
+	return key, true
+}
+
+// issueAPIKey creates and stores a new key, returning it with its plaintext token.
+func issueAPIKey(userID, name string, scopes []string) (*APIKey, string, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, "", err
+	}
+	token, secret, err := newAPIKeyToken(id)
+	if err != nil {
+		return nil, "", err
+	}
+	key := &APIKey{
+		ID:         id,
+		UserID:     userID,
+		Name:       name,
+		Scopes:     slices.Clone(scopes),
+		SecretHash: hashSecret(secret),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := dataStore.PutAPIKey(key); err != nil {
+		return nil, "", err
+	}
+	return key, token, nil
+}
+
+// revokeAPIKey marks a key as revoked so it can no longer authenticate.
+func revokeAPIKey(key *APIKey) error {
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+	return dataStore.PutAPIKey(key)
+}
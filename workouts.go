@@ -0,0 +1,407 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/pachev/gorack/rack"
+)
+
+// dateLayout is the format for workout dates in requests and trend responses.
+const dateLayout = "2006-01-02"
+
+// Workout is a logged training session.
+type Workout struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"userId"`
+	PerformedAt time.Time    `json:"performedAt"`
+	InventoryID string       `json:"inventory,omitempty"`
+	BarWeight   int          `json:"barWeight"`
+	Notes       string       `json:"notes,omitempty"`
+	Sets        []WorkoutSet `json:"sets"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+// WorkoutSet is a single logged set. Loading is the plate breakdown computed
+// when the set was logged, so later changes to an inventory don't rewrite history.
+type WorkoutSet struct {
+	Exercise string                      `json:"exercise"`
+	Reps     int                         `json:"reps"`
+	Weight   int                         `json:"weight"`
+	RPE      float64                     `json:"rpe,omitempty"`
+	Notes    string                      `json:"notes,omitempty"`
+	Loading  *rack.ReturnedValueStandard `json:"loading,omitempty"`
+}
+
+// EstimatedOneRepMax returns the Epley estimate for the set.
+func (ws *WorkoutSet) EstimatedOneRepMax() float64 {
+	if ws.Reps <= 1 {
+		return float64(ws.Weight)
+	}
+	return float64(ws.Weight) * (1 + float64(ws.Reps)/30)
+}
+
+// WorkoutRequest is the payload for logging a session.
+type WorkoutRequest struct {
+	PerformedAt string       `json:"performedAt"` // YYYY-MM-DD or RFC 3339, defaults to now
+	Inventory   string       `json:"inventory,omitempty"`
+	BarWeight   int          `json:"barWeight,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
+	Sets        []WorkoutSet `json:"sets"`
+
+	performedAt time.Time
+	plates      rack.RackInputStandard
+}
+
+// Bind validates a session and resolves the plates used to compute loadings.
+func (wr *WorkoutRequest) Bind(r *http.Request) error {
+	if wr.PerformedAt == "" {
+		wr.performedAt = time.Now().UTC()
+	} else {
+		performedAt, err := parseDate(wr.PerformedAt)
+		if err != nil {
+			return errors.New("performedAt must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+		}
+		wr.performedAt = performedAt
+	}
+
+	if len(wr.Sets) == 0 {
+		return errors.New("at least one set is required")
+	}
+	// Every set is calculated and stored, so sessions are capped like batches
+	if maxSets := getEnvInt("WORKOUT_MAX_SETS", 100); len(wr.Sets) > maxSets {
+		return fmt.Errorf("a session cannot have more than %d sets", maxSets)
+	}
+	for i := range wr.Sets {
+		set := &wr.Sets[i]
+		set.Exercise = strings.TrimSpace(set.Exercise)
+		set.Loading = nil // Always computed server-side
+		if set.Exercise == "" {
+			return fmt.Errorf("set %d: exercise is required", i+1)
+		}
+		if set.Reps <= 0 {
+			return fmt.Errorf("set %d: reps must be a positive integer", i+1)
+		}
+		if set.Weight < 0 {
+			return fmt.Errorf("set %d: weight cannot be negative", i+1)
+		}
+		if set.Weight > rack.MaxSearchWeight {
+			return fmt.Errorf("set %d: weight cannot be more than %d", i+1, rack.MaxSearchWeight)
+		}
+		if set.RPE != 0 && (set.RPE < 1 || set.RPE > 10) {
+			return fmt.Errorf("set %d: rpe must be between 1 and 10", i+1)
+		}
+	}
+
+	wr.plates = rack.AssumeDefaults()
+	wr.plates.BarWeight = wr.BarWeight
+	if wr.Inventory != "" {
+		inv, err := lookupInventory(r.Context(), wr.Inventory)
+		if err != nil {
+			return errors.New("inventory not found")
+		}
+		inv.Apply(&wr.plates)
+	}
+	if wr.plates.BarWeight == 0 {
+		wr.plates.BarWeight = rack.AssumeDefaults().BarWeight
+	}
+	if wr.plates.BarWeight < 0 {
+		return errors.New("bar weight cannot be negative")
+	}
+	return nil
+}
+
+// WorkoutFilter narrows a workout history query.
+type WorkoutFilter struct {
+	Exercise string
+	From     time.Time // Inclusive, zero means unbounded
+	To       time.Time // Exclusive, zero means unbounded
+}
+
+// parseWorkoutFilter reads exercise, from and to query parameters.
+// A plain date for "to" includes that whole day.
+func parseWorkoutFilter(r *http.Request) (WorkoutFilter, error) {
+	query := r.URL.Query()
+	filter := WorkoutFilter{Exercise: strings.TrimSpace(query.Get("exercise"))}
+	if from := query.Get("from"); from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return filter, errors.New("invalid 'from' parameter: must be YYYY-MM-DD or RFC 3339")
+		}
+		filter.From = t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return filter, errors.New("invalid 'to' parameter: must be YYYY-MM-DD or RFC 3339")
+		}
+		if len(to) == len(dateLayout) {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = t
+	}
+	return filter, nil
+}
+
+// Apply returns the user's sessions that match the filter, oldest first.
+// When filtering by exercise, sessions only keep the matching sets.
+func (f WorkoutFilter) Apply(workouts []*Workout) []*Workout {
+	matched := []*Workout{}
+	for _, workout := range workouts {
+		if !f.From.IsZero() && workout.PerformedAt.Before(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && !workout.PerformedAt.Before(f.To) {
+			continue
+		}
+		if f.Exercise != "" {
+			var sets []WorkoutSet
+			for _, set := range workout.Sets {
+				if strings.EqualFold(set.Exercise, f.Exercise) {
+					sets = append(sets, set)
+				}
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			workout.Sets = sets
+		}
+		matched = append(matched, workout)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].PerformedAt.Before(matched[j].PerformedAt)
+	})
+	return matched
+}
+
+// TrendPoint is the best estimated one-rep max for a lift on one day.
+type TrendPoint struct {
+	Date               string  `json:"date"`
+	EstimatedOneRepMax float64 `json:"estimatedOneRepMax"`
+	Weight             int     `json:"weight"`
+	Reps               int     `json:"reps"`
+}
+
+// ExerciseTrend is the estimated one-rep max history for a single lift.
+type ExerciseTrend struct {
+	Exercise string       `json:"exercise"`
+	Points   []TrendPoint `json:"points"`
+}
+
+// buildTrends computes per-lift estimated one-rep max trends from sessions
+// sorted oldest first, keeping the best set for each day.
+func buildTrends(workouts []*Workout) []*ExerciseTrend {
+	trends := map[string]*ExerciseTrend{}
+	for _, workout := range workouts {
+		date := workout.PerformedAt.Format(dateLayout)
+		for _, set := range workout.Sets {
+			key := strings.ToLower(set.Exercise)
+			trend, found := trends[key]
+			if !found {
+				trend = &ExerciseTrend{Exercise: set.Exercise, Points: []TrendPoint{}}
+				trends[key] = trend
+			}
+
+			e1rm := math.Round(set.EstimatedOneRepMax()*10) / 10
+			last := len(trend.Points) - 1
+			if last >= 0 && trend.Points[last].Date == date {
+				if e1rm > trend.Points[last].EstimatedOneRepMax {
+					trend.Points[last] = TrendPoint{Date: date, EstimatedOneRepMax: e1rm, Weight: set.Weight, Reps: set.Reps}
+				}
+				continue
+			}
+			trend.Points = append(trend.Points, TrendPoint{Date: date, EstimatedOneRepMax: e1rm, Weight: set.Weight, Reps: set.Reps})
+		}
+	}
+
+	result := make([]*ExerciseTrend, 0, len(trends))
+	for _, trend := range trends {
+		result = append(result, trend)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Exercise < result[j].Exercise
+	})
+	return result
+}
+
+// WorkoutRoutes mounts the workout logging endpoints.
+func WorkoutRoutes(r chi.Router) {
+	r.With(RequireScope(ScopeRead)).Get("/", ListWorkouts)
+	r.With(RequireScope(ScopeWrite)).Post("/", CreateWorkout)
+	r.With(RequireScope(ScopeRead)).Get("/trends", GetWorkoutTrends)
+	r.With(RequireScope(ScopeRead)).Get("/{workoutID}", GetWorkout)
+	r.With(RequireScope(ScopeWrite)).Delete("/{workoutID}", DeleteWorkout)
+}
+
+// CreateWorkout godoc
+// @Summary      Log a workout session
+// @Description  Stores the sets of a session together with the plate loading for each set's weight
+// @Tags         Workouts
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      WorkoutRequest  true  "Session date, plates and sets"
+// @Success      201      {object}  Workout
+// @Failure      400      {object}  ErrResponse
+// @Failure      401      {object}  ErrResponse
+// @Failure      403      {object}  ErrResponse
+// @Failure      500      {object}  ErrResponse
+// @Router       /workouts [post]
+func CreateWorkout(w http.ResponseWriter, r *http.Request) {
+	input := &WorkoutRequest{}
+	if err := render.Bind(r, input); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	for i := range input.Sets {
+		set := &input.Sets[i]
+		// Nothing to load when the set is the empty bar or lighter
+		if set.Weight <= input.plates.BarWeight {
+			continue
+		}
+		plates := input.plates
+		plates.DesiredWeight = set.Weight
+		loading, err := observeSolver(r.Context(), &plates, false)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Calculating loading for workout set failed", "err", err, "input", plates)
+			render.Render(w, r, ErrInternal())
+			return
+		}
+		set.Loading = loading
+	}
+
+	id, err := newID()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Generating workout ID failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	workout := &Workout{
+		ID:          id,
+		UserID:      PrincipalFrom(r.Context()).User.ID,
+		PerformedAt: input.performedAt,
+		InventoryID: input.Inventory,
+		BarWeight:   input.plates.BarWeight,
+		Notes:       input.Notes,
+		Sets:        input.Sets,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := dataStore.PutWorkout(workout); err != nil {
+		slog.ErrorContext(r.Context(), "Saving workout failed", "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, workout)
+}
+
+// ListWorkouts godoc
+// @Summary      Query workout history
+// @Description  Returns logged sessions, oldest first. Filtering by exercise keeps only that lift's sets.
+// @Tags         Workouts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        exercise  query     string  false  "Exercise name (case-insensitive)"
+// @Param        from      query     string  false  "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param        to        query     string  false  "End date, inclusive for plain dates (YYYY-MM-DD or RFC 3339)"
+// @Success      200       {array}   Workout
+// @Failure      400       {object}  ErrResponse
+// @Failure      401       {object}  ErrResponse
+// @Failure      403       {object}  ErrResponse
+// @Router       /workouts [get]
+func ListWorkouts(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseWorkoutFilter(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	workouts := dataStore.ListWorkouts(PrincipalFrom(r.Context()).User.ID)
+	render.JSON(w, r, filter.Apply(workouts))
+}
+
+// GetWorkoutTrends godoc
+// @Summary      Estimated one-rep max trends
+// @Description  Returns the best Epley estimated one-rep max per day for each lift
+// @Tags         Workouts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        exercise  query     string  false  "Exercise name (case-insensitive)"
+// @Param        from      query     string  false  "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param        to        query     string  false  "End date, inclusive for plain dates (YYYY-MM-DD or RFC 3339)"
+// @Success      200       {array}   ExerciseTrend
+// @Failure      400       {object}  ErrResponse
+// @Failure      401       {object}  ErrResponse
+// @Failure      403       {object}  ErrResponse
+// @Router       /workouts/trends [get]
+func GetWorkoutTrends(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseWorkoutFilter(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	workouts := dataStore.ListWorkouts(PrincipalFrom(r.Context()).User.ID)
+	render.JSON(w, r, buildTrends(filter.Apply(workouts)))
+}
+
+// GetWorkout godoc
+// @Summary      Get a workout session
+// @Tags         Workouts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        workoutID  path      string  true  "Workout ID"
+// @Success      200        {object}  Workout
+// @Failure      401        {object}  ErrResponse
+// @Failure      403        {object}  ErrResponse
+// @Failure      404        {object}  ErrResponse
+// @Router       /workouts/{workoutID} [get]
+func GetWorkout(w http.ResponseWriter, r *http.Request) {
+	workout, err := dataStore.GetWorkout(chi.URLParam(r, "workoutID"))
+	if err != nil || workout.UserID != PrincipalFrom(r.Context()).User.ID {
+		render.Render(w, r, ErrNotFound(errors.New("workout not found")))
+		return
+	}
+	render.JSON(w, r, workout)
+}
+
+// DeleteWorkout godoc
+// @Summary      Delete a workout session
+// @Tags         Workouts
+// @Security     ApiKeyAuth
+// @Param        workoutID  path  string  true  "Workout ID"
+// @Success      204
+// @Failure      401  {object}  ErrResponse
+// @Failure      403  {object}  ErrResponse
+// @Failure      404  {object}  ErrResponse
+// @Failure      500  {object}  ErrResponse
+// @Router       /workouts/{workoutID} [delete]
+func DeleteWorkout(w http.ResponseWriter, r *http.Request) {
+	workout, err := dataStore.GetWorkout(chi.URLParam(r, "workoutID"))
+	if err != nil || workout.UserID != PrincipalFrom(r.Context()).User.ID {
+		render.Render(w, r, ErrNotFound(errors.New("workout not found")))
+		return
+	}
+	if err := dataStore.DeleteWorkout(workout.ID); err != nil {
+		slog.ErrorContext(r.Context(), "Deleting workout failed", "workout", workout.ID, "err", err)
+		render.Render(w, r, ErrInternal())
+		return
+	}
+	render.NoContent(w, r)
+}
+
+// parseDate accepts a plain date or an RFC 3339 timestamp.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
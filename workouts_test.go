@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// workoutRouter mounts the workout routes behind Authenticate, as the API does.
+func workoutRouter() http.Handler {
+	router := chi.NewRouter()
+	router.With(Authenticate).Route("/v1/api/workouts", WorkoutRoutes)
+	return router
+}
+
+// logWorkout posts a session and returns the stored workout.
+func logWorkout(t *testing.T, router http.Handler, token, body string) *Workout {
+	t.Helper()
+	rec := serveRequest(router, "POST", "/v1/api/workouts", body, token)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /workouts: %d %s", rec.Code, rec.Body)
+	}
+	workout := &Workout{}
+	if err := json.Unmarshal(rec.Body.Bytes(), workout); err != nil {
+		t.Fatal(err)
+	}
+	return workout
+}
+
+func TestEstimatedOneRepMax(t *testing.T) {
+	tests := []struct {
+		weight, reps int
+		want         float64
+	}{
+		{300, 1, 300},
+		{225, 5, 262.5},
+		{250, 3, 275},
+		{135, 10, 180},
+	}
+	for _, tt := range tests {
+		set := WorkoutSet{Weight: tt.weight, Reps: tt.reps}
+		if got := set.EstimatedOneRepMax(); got != tt.want {
+			t.Errorf("%d x %d: e1RM = %v, want %v", tt.weight, tt.reps, got, tt.want)
+		}
+	}
+}
+
+func TestCreateWorkout(t *testing.T) {
+	useTestStore(t)
+	router := workoutRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	workout := logWorkout(t, router, token, `{"performedAt": "2026-10-01", "notes": "Heavy day", "sets": [
+		{"exercise": " Squat ", "reps": 5, "weight": 225, "rpe": 8},
+		{"exercise": "Squat", "reps": 10, "weight": 45}
+	]}`)
+	if workout.PerformedAt.Format(dateLayout) != "2026-10-01" || workout.BarWeight != 45 || len(workout.Sets) != 2 {
+		t.Fatalf("workout = %+v", workout)
+	}
+	if set := workout.Sets[0]; set.Exercise != "Squat" || set.Loading == nil || set.Loading.AchievedWeight != 225 {
+		t.Errorf("first set = %+v, want Squat loaded to 225", set)
+	}
+	if workout.Sets[1].Loading != nil {
+		t.Error("empty bar set has a loading")
+	}
+	if stored, err := dataStore.GetWorkout(workout.ID); err != nil || len(stored.Sets) != 2 {
+		t.Errorf("stored workout = %+v, %v", stored, err)
+	}
+
+	if rec := serveRequest(router, "POST", "/v1/api/workouts", `{"sets": [{"exercise": "Squat", "reps": 5, "weight": 225}]}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a key: %d, want 401", rec.Code)
+	}
+
+	t.Setenv("WORKOUT_MAX_SETS", "2")
+	set := `{"exercise": "Squat", "reps": 5, "weight": 225}`
+	tests := []struct {
+		name, body, error string
+	}{
+		{"no sets", `{"sets": []}`, "at least one set is required"},
+		{"too many sets", `{"sets": [` + strings.Repeat(set+",", 2) + set + `]}`, "a session cannot have more than 2 sets"},
+		{"too heavy", `{"sets": [{"exercise": "Squat", "reps": 1, "weight": 20001}]}`, "set 1: weight cannot be more than 20000"},
+		{"negative weight", `{"sets": [{"exercise": "Squat", "reps": 1, "weight": -5}]}`, "set 1: weight cannot be negative"},
+		{"no exercise", `{"sets": [` + set + `, {"reps": 5, "weight": 225}]}`, "set 2: exercise is required"},
+		{"no reps", `{"sets": [{"exercise": "Squat", "weight": 225}]}`, "set 1: reps must be a positive integer"},
+		{"rpe", `{"sets": [{"exercise": "Squat", "reps": 5, "weight": 225, "rpe": 11}]}`, "set 1: rpe must be between 1 and 10"},
+		{"date", `{"performedAt": "October 1st", "sets": [` + set + `]}`, "performedAt must be a date (YYYY-MM-DD) or RFC 3339 timestamp"},
+		{"inventory", `{"inventory": "missing", "sets": [` + set + `]}`, "inventory not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRequest(router, "POST", "/v1/api/workouts", tt.body, token)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if got := decodeError(t, rec).ErrorText; got != tt.error {
+				t.Errorf("error = %q, want %q", got, tt.error)
+			}
+		})
+	}
+}
+
+func TestListWorkouts(t *testing.T) {
+	useTestStore(t)
+	router := workoutRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+	_, otherToken := newTestUser(t, "alex", AllScopes...)
+
+	logWorkout(t, router, token, `{"performedAt": "2026-10-01", "sets": [{"exercise": "Squat", "reps": 5, "weight": 245}]}`)
+	logWorkout(t, router, token, `{"performedAt": "2026-08-31T23:59:00Z", "sets": [{"exercise": "Squat", "reps": 5, "weight": 205}]}`)
+	logWorkout(t, router, token, `{"performedAt": "2026-09-30T23:00:00Z", "sets": [{"exercise": "Bench", "reps": 5, "weight": 185}, {"exercise": "squat", "reps": 5, "weight": 235}]}`)
+	logWorkout(t, router, token, `{"performedAt": "2026-09-01", "sets": [{"exercise": "Bench", "reps": 5, "weight": 175}]}`)
+	logWorkout(t, router, otherToken, `{"performedAt": "2026-09-15", "sets": [{"exercise": "Squat", "reps": 5, "weight": 315}]}`)
+
+	list := func(query string) []*Workout {
+		t.Helper()
+		rec := serveRequest(router, "GET", "/v1/api/workouts"+query, "", token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /workouts%s: %d %s", query, rec.Code, rec.Body)
+		}
+		var workouts []*Workout
+		if err := json.Unmarshal(rec.Body.Bytes(), &workouts); err != nil {
+			t.Fatal(err)
+		}
+		return workouts
+	}
+	weights := func(workouts []*Workout) []int {
+		weights := []int{}
+		for _, workout := range workouts {
+			for _, set := range workout.Sets {
+				weights = append(weights, set.Weight)
+			}
+		}
+		return weights
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"everything, oldest first", "", []int{205, 175, 185, 235, 245}},
+		{"plain dates include the last day", "?from=2026-09-01&to=2026-09-30", []int{175, 185, 235}},
+		{"timestamps are exact", "?from=2026-09-01T00:00:00Z&to=2026-09-30T23:00:00Z", []int{175}},
+		{"exercise keeps only its sets", "?exercise=SQUAT", []int{205, 235, 245}},
+		{"both", "?exercise=bench&to=2026-09-01", []int{175}},
+		{"nothing", "?exercise=deadlift", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weights(list(tt.query)); !slices.Equal(got, tt.want) {
+				t.Errorf("weights = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if rec := serveRequest(router, "GET", "/v1/api/workouts?from=yesterday", "", token); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad from: %d, want 400", rec.Code)
+	}
+}
+
+func TestWorkoutTrends(t *testing.T) {
+	useTestStore(t)
+	router := workoutRouter()
+	_, token := newTestUser(t, "sam", AllScopes...)
+
+	logWorkout(t, router, token, `{"performedAt": "2026-09-01", "sets": [
+		{"exercise": "Squat", "reps": 5, "weight": 225},
+		{"exercise": "Squat", "reps": 3, "weight": 250},
+		{"exercise": "Bench", "reps": 8, "weight": 155}
+	]}`)
+	logWorkout(t, router, token, `{"performedAt": "2026-09-08", "sets": [{"exercise": "squat", "reps": 1, "weight": 285}]}`)
+
+	rec := serveRequest(router, "GET", "/v1/api/workouts/trends", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /workouts/trends: %d %s", rec.Code, rec.Body)
+	}
+	var trends []ExerciseTrend
+	if err := json.Unmarshal(rec.Body.Bytes(), &trends); err != nil {
+		t.Fatal(err)
+	}
+	// The best set each day, lifts sorted by name; 155 x 8 is 196.33, rounded to 196.3
+	want := []ExerciseTrend{
+		{Exercise: "Bench", Points: []TrendPoint{{Date: "2026-09-01", EstimatedOneRepMax: 196.3, Weight: 155, Reps: 8}}},
+		{Exercise: "Squat", Points: []TrendPoint{
+			{Date: "2026-09-01", EstimatedOneRepMax: 275, Weight: 250, Reps: 3},
+			{Date: "2026-09-08", EstimatedOneRepMax: 285, Weight: 285, Reps: 1},
+		}},
+	}
+	if len(trends) != len(want) {
+		t.Fatalf("trends = %+v, want %+v", trends, want)
+	}
+	for i := range want {
+		if trends[i].Exercise != want[i].Exercise || len(trends[i].Points) != len(want[i].Points) {
+			t.Fatalf("trend %d = %+v, want %+v", i, trends[i], want[i])
+		}
+		for j, point := range want[i].Points {
+			if trends[i].Points[j] != point {
+				t.Errorf("%s point %d = %+v, want %+v", want[i].Exercise, j, trends[i].Points[j], point)
+			}
+		}
+	}
+
+	rec = serveRequest(router, "GET", "/v1/api/workouts/trends?exercise=bench&from=2026-09-02", "", token)
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("filtered trends = %s, want []", rec.Body)
+	}
+}